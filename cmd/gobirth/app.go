@@ -0,0 +1,469 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	"github.com/rafakmp18/gobirth/internal/gobirth/adapters/calendar/cache"
+	"github.com/rafakmp18/gobirth/internal/gobirth/adapters/calendar/caldav"
+	"github.com/rafakmp18/gobirth/internal/gobirth/adapters/calendar/csvfile"
+	"github.com/rafakmp18/gobirth/internal/gobirth/adapters/calendar/file"
+	"github.com/rafakmp18/gobirth/internal/gobirth/adapters/calendar/filter"
+	"github.com/rafakmp18/gobirth/internal/gobirth/adapters/calendar/google"
+	"github.com/rafakmp18/gobirth/internal/gobirth/adapters/calendar/ics"
+	"github.com/rafakmp18/gobirth/internal/gobirth/adapters/calendar/multi"
+	"github.com/rafakmp18/gobirth/internal/gobirth/adapters/calendar/namedays"
+	"github.com/rafakmp18/gobirth/internal/gobirth/adapters/calendar/vcard"
+	clocksys "github.com/rafakmp18/gobirth/internal/gobirth/adapters/clock/system"
+	"github.com/rafakmp18/gobirth/internal/gobirth/adapters/message/template"
+	"github.com/rafakmp18/gobirth/internal/gobirth/adapters/secrets/encfile"
+	secretenv "github.com/rafakmp18/gobirth/internal/gobirth/adapters/secrets/env"
+	"github.com/rafakmp18/gobirth/internal/gobirth/adapters/secrets/keyring"
+	"github.com/rafakmp18/gobirth/internal/gobirth/application"
+	"github.com/rafakmp18/gobirth/internal/gobirth/config"
+	"google.golang.org/api/calendar/v3"
+	"google.golang.org/api/people/v1"
+)
+
+// commandFlags is the flag set shared by every command that needs the
+// layered configuration.
+type commandFlags struct {
+	*flag.FlagSet
+	configPath *string
+}
+
+func newCommandFlags(name string) commandFlags {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	cf := commandFlags{
+		FlagSet:    fs,
+		configPath: fs.String("config", "", "Path to config file (default ~/.config/gobirth/config.yaml, or $GOBIRTH_CONFIG)"),
+	}
+	registerConfigFlags(fs)
+	return cf
+}
+
+// load parses args and returns the validated configuration.
+func (cf commandFlags) load(args []string) (config.Config, error) {
+	_ = cf.Parse(args)
+
+	cfg, err := loadConfig(*cf.configPath, cf.FlagSet)
+	if err != nil {
+		return config.Config{}, err
+	}
+	if err := validateConfig(cfg); err != nil {
+		return config.Config{}, fmt.Errorf("invalid configuration:\n%w", err)
+	}
+	return cfg, nil
+}
+
+// validateConfig checks cfg, parsing the message templates with the
+// generator's functions.
+func validateConfig(cfg config.Config) error {
+	return errors.Join(cfg.Validate(), cfg.Message.CheckTemplates(template.Check))
+}
+
+// registerConfigFlags defines the flags that can override config values.
+// Their defaults are only used for help output: loadConfig applies a flag
+// only when it was set explicitly, so env vars and the config file still win
+// over flag defaults.
+func registerConfigFlags(fs *flag.FlagSet) {
+	def := config.Default()
+
+	fs.String("calendar-file", def.Calendar.File, "Path to a JSON file with calendar events")
+	fs.Bool("dry-run", def.DryRun, "If true, messages will not be sent (only printed)")
+	fs.Int("max", def.MaxPerRun, "Maximum number of greetings to process per run")
+	fs.String("emoji", def.Message.Emoji, "Emoji to include in template messages")
+	fs.String("template", def.Message.Template, "Message template (text/template with {{.Name}}, {{.Context}}, {{.Emoji}}, {{.Occasion}}, {{.Years}})")
+	fs.String("group-template", def.Message.GroupTemplate, "Message template for posts in the group named by an event's \"group:\" line")
+	fs.Bool("joint", def.Message.Joint, "Send the people of an event one shared message instead of one each")
+	fs.String("calendar-provider", def.Calendar.Provider, "Calendar provider: file|ics|vcard|csv|caldav|google|google-contacts|multi")
+	fs.Bool("calendar-cache", def.Calendar.Cache.Enabled, "Keep the last successful fetch on disk and use it when the calendar is unreachable")
+	fs.String("calendar-sources", "", "Comma-separated providers merged by --calendar-provider=multi, highest precedence first")
+	fs.String("name-days", "", "Comma-separated countries whose name days are greeted, e.g. es,gr,pl (common names on fixed dates only)")
+	fs.String("ics", def.Calendar.ICS, "Path to an .ics file or a directory of .ics files (--calendar-provider=ics)")
+	fs.String("vcard", def.Calendar.VCard, "Path to a .vcf file or a directory of .vcf files (--calendar-provider=vcard)")
+	fs.String("csv", def.Calendar.CSV.Path, "Path to a CSV/TSV contact list with a header row (--calendar-provider=csv)")
+	fs.String("caldav-url", "", "CalDAV server URL, e.g. https://cloud.example.com/remote.php/dav (--calendar-provider=caldav)")
+	fs.String("caldav-user", "", "CalDAV username (password from $GOBIRTH_CALDAV_PASSWORD or the secret store)")
+	fs.String("caldav-calendar", "", "CalDAV calendar display name")
+	fs.String("google-calendar", def.Calendar.Google.Calendar, "Google Calendar name to use (e.g. gobirth)")
+	fs.String("google-calendar-id", "", "Google Calendar ID; skips the lookup by name (needed for service accounts)")
+	fs.String("google-auth", def.Calendar.Google.Auth, "Google authentication: oauth (browser) | device (headless; Google refuses it the Calendar and Contacts scopes) | service-account | adc")
+	fs.String("google-service-account", "", "Path to a Google service-account JSON key (--google-auth=service-account)")
+	fs.String("google-subject", "", "User to impersonate with domain-wide delegation (--google-auth=service-account)")
+	fs.String("google-credentials", "", "Path to Google OAuth credentials.json (default ~/.config/gobirth/credentials.json)")
+	fs.String("google-token", "", "Path to Google OAuth token.json (default ~/.config/gobirth/token.json)")
+	fs.Bool("google-sync", def.Calendar.Google.Sync, "Keep a local copy of the Google calendar and only fetch changes")
+	fs.String("google-write-back", "", "Record sent greetings on the Google event: property|description")
+	fs.String("secrets-backend", def.Secrets.Backend, "Where credentials are stored: file (plain JSON) | keyring | encrypted-file | env")
+	fs.String("timezone", def.Timezone, "IANA timezone used to decide what \"today\" is")
+	fs.String("output", def.Output, "Report format: text|json|table")
+}
+
+func loadConfig(path string, fs *flag.FlagSet) (config.Config, error) {
+	required := true
+	if path == "" {
+		path = os.Getenv("GOBIRTH_CONFIG")
+	}
+	if path == "" {
+		path = config.DefaultPath()
+		required = false
+	}
+
+	cfg, err := config.Load(path, required, os.LookupEnv)
+	if err != nil {
+		return config.Config{}, err
+	}
+
+	var flagErr error
+	fs.Visit(func(f *flag.Flag) {
+		if err := applyFlag(&cfg, f.Name, f.Value.String()); err != nil && flagErr == nil {
+			flagErr = err
+		}
+	})
+	cfg.ExpandPaths()
+
+	return cfg, flagErr
+}
+
+func applyFlag(cfg *config.Config, name, value string) error {
+	switch name {
+	case "calendar-file":
+		cfg.Calendar.File = value
+	case "dry-run":
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid --dry-run %q", value)
+		}
+		cfg.DryRun = b
+	case "max":
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("invalid --max %q", value)
+		}
+		cfg.MaxPerRun = n
+	case "emoji":
+		cfg.Message.Emoji = value
+	case "template":
+		cfg.Message.Template = value
+	case "group-template":
+		cfg.Message.GroupTemplate = value
+	case "joint":
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid --joint %q", value)
+		}
+		cfg.Message.Joint = b
+	case "ics":
+		cfg.Calendar.ICS = value
+	case "vcard":
+		cfg.Calendar.VCard = value
+	case "csv":
+		cfg.Calendar.CSV.Path = value
+	case "calendar-provider":
+		cfg.Calendar.Provider = value
+	case "calendar-cache":
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid --calendar-cache %q", value)
+		}
+		cfg.Calendar.Cache.Enabled = b
+	case "calendar-sources":
+		cfg.Calendar.Sources = config.SplitList(value)
+	case "name-days":
+		cfg.Calendar.NameDays = config.SplitList(value)
+	case "caldav-url":
+		cfg.Calendar.CalDAV.URL = value
+	case "caldav-user":
+		cfg.Calendar.CalDAV.Username = value
+	case "caldav-calendar":
+		cfg.Calendar.CalDAV.Calendar = value
+	case "google-calendar":
+		cfg.Calendar.Google.Calendar = value
+	case "google-calendar-id":
+		cfg.Calendar.Google.CalendarID = value
+	case "google-auth":
+		cfg.Calendar.Google.Auth = value
+	case "google-service-account":
+		cfg.Calendar.Google.ServiceAccount = value
+	case "google-subject":
+		cfg.Calendar.Google.Subject = value
+	case "google-credentials":
+		cfg.Calendar.Google.Credentials = value
+	case "google-token":
+		cfg.Calendar.Google.Token = value
+	case "google-sync":
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return fmt.Errorf("invalid --google-sync %q", value)
+		}
+		cfg.Calendar.Google.Sync = b
+	case "google-write-back":
+		cfg.Calendar.Google.WriteBack = value
+	case "secrets-backend":
+		cfg.Secrets.Backend = value
+	case "timezone":
+		cfg.Timezone = value
+	case "output":
+		cfg.Output = value
+	}
+	return nil
+}
+
+// buildSecretStore returns the configured secret store, or nil for the
+// "file" backend where each credential keeps its own plain file.
+func buildSecretStore(cfg config.Config) (application.SecretStore, error) {
+	switch cfg.Secrets.Backend {
+	case "file":
+		return nil, nil
+	case "keyring":
+		return keyring.Store{}, nil
+	case "env":
+		return secretenv.Store{}, nil
+	case "encrypted-file":
+		pass := os.Getenv("GOBIRTH_SECRETS_PASSPHRASE")
+		if pass == "" {
+			return nil, fmt.Errorf("GOBIRTH_SECRETS_PASSPHRASE must be set for secrets.backend=encrypted-file")
+		}
+		return encfile.New(cfg.Secrets.Path, pass), nil
+	}
+
+	return nil, fmt.Errorf("invalid secrets backend %q", cfg.Secrets.Backend)
+}
+
+// googleAuthConfig builds the Google auth settings for the configured
+// provider; google-contacts keeps its own token because of its scope.
+func googleAuthConfig(cfg config.Config, secrets application.SecretStore) google.AuthConfig {
+	authCfg := google.AuthConfig{
+		CredentialsPath: cfg.Calendar.Google.Credentials,
+		TokenPath:       cfg.Calendar.Google.Token,
+		Secrets:         secrets,
+		Method:          google.AuthMethod(cfg.Calendar.Google.Auth),
+
+		ServiceAccountKeyPath: cfg.Calendar.Google.ServiceAccount,
+		Subject:               cfg.Calendar.Google.Subject,
+	}
+
+	switch {
+	case cfg.Calendar.Provider == "google-contacts":
+		authCfg.TokenPath = cfg.Calendar.Google.ContactsToken
+		authCfg.TokenKey = google.ContactsTokenSecretKey
+		authCfg.Scopes = []string{people.ContactsReadonlyScope}
+	case cfg.Calendar.Google.WriteBack != "":
+		// Write-back patches events, which the read-only scope forbids.
+		authCfg.Scopes = []string{calendar.CalendarEventsScope}
+	}
+	return authCfg
+}
+
+// googleProvider builds the Google Calendar provider; with calendar.google.sync
+// it answers from the local copy, refreshed at most once a minute.
+func googleProvider(cfg config.Config, svc *calendar.Service) *google.Provider {
+	p := &google.Provider{
+		Svc:          svc,
+		CalendarName: cfg.Calendar.Google.Calendar,
+		CalendarID:   cfg.Calendar.Google.CalendarID,
+		WriteBack:    google.WriteBack(cfg.Calendar.Google.WriteBack),
+		Filter:       calendarFilter(cfg.Calendar.Filter),
+	}
+	if cfg.Calendar.Google.Sync {
+		p.StatePath = cfg.Calendar.Google.SyncState
+		p.MaxAge = time.Minute
+	}
+	return p
+}
+
+// calendarFilter converts calendar.filter, already validated. An empty one
+// keeps every event.
+func calendarFilter(c config.FilterConfig) *filter.Filter {
+	f := &filter.Filter{
+		IncludeColors: c.IncludeColors,
+		ExcludeColors: c.ExcludeColors,
+		IncludeTags:   c.IncludeTags,
+		ExcludeTags:   c.ExcludeTags,
+		Kind:          filter.Kind(c.Kind),
+	}
+	if c.IncludeTitle != "" {
+		f.IncludeTitle = regexp.MustCompile(c.IncludeTitle)
+	}
+	if c.ExcludeTitle != "" {
+		f.ExcludeTitle = regexp.MustCompile(c.ExcludeTitle)
+	}
+	return f
+}
+
+func csvProvider(c config.CSVConfig) csvfile.Provider {
+	var comma rune
+	switch c.Delimiter {
+	case "":
+	case `\t`:
+		comma = '\t'
+	default:
+		comma, _ = utf8.DecodeRuneInString(c.Delimiter)
+	}
+
+	return csvfile.Provider{
+		Path:  c.Path,
+		Comma: comma,
+		Columns: csvfile.Columns{
+			Name:     c.Columns.Name,
+			Birthday: c.Columns.Birthday,
+			Phone:    c.Columns.Phone,
+			Context:  c.Columns.Context,
+		},
+		DateFormats: c.DateFormats,
+	}
+}
+
+// senderTokenSecretKey is the secret store key the sender token is read
+// from when it is not set in the config.
+const senderTokenSecretKey = "sender-token"
+
+// caldavPassword returns the configured CalDAV password, falling back to the
+// secret store entry caldav.PasswordSecretKey.
+func caldavPassword(ctx context.Context, cfg config.Config) (string, error) {
+	return storedSecret(ctx, cfg, "caldav password", cfg.Calendar.CalDAV.Password, caldav.PasswordSecretKey)
+}
+
+// senderToken returns the configured sender token, falling back to the
+// secret store entry senderTokenSecretKey.
+func senderToken(ctx context.Context, cfg config.Config) (string, error) {
+	return storedSecret(ctx, cfg, "sender token", cfg.Sender.Token, senderTokenSecretKey)
+}
+
+// storedSecret returns value when the config sets it, or else the secret
+// store entry key; a missing entry is not an error.
+func storedSecret(ctx context.Context, cfg config.Config, what, value, key string) (string, error) {
+	if value != "" {
+		return value, nil
+	}
+
+	secrets, err := buildSecretStore(cfg)
+	if err != nil || secrets == nil {
+		return "", err
+	}
+
+	b, err := secrets.Get(ctx, key)
+	if errors.Is(err, application.ErrSecretNotFound) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", what, err)
+	}
+	return string(b), nil
+}
+
+// buildCalendar returns the configured provider, wrapped in the on-disk
+// cache when calendar.cache is enabled.
+func buildCalendar(ctx context.Context, cfg config.Config) (application.CalendarProvider, error) {
+	cal, err := buildCalendarProvider(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.Calendar.Cache.Enabled {
+		cal = &cache.Provider{
+			Upstream: cal,
+			Path:     cfg.Calendar.Cache.Path,
+			Window:   time.Duration(cfg.Calendar.Cache.Days) * 24 * time.Hour,
+		}
+	}
+	if len(cfg.Calendar.NameDays) > 0 {
+		return namedays.New(cal, cfg.Calendar.NameDays)
+	}
+	return cal, nil
+}
+
+func buildCalendarProvider(ctx context.Context, cfg config.Config) (application.CalendarProvider, error) {
+	switch cfg.Calendar.Provider {
+	case "multi":
+		var sources []multi.Source
+		for _, name := range cfg.Calendar.Sources {
+			srcCfg := cfg
+			srcCfg.Calendar.Provider = name
+			cal, err := buildCalendarProvider(ctx, srcCfg)
+			if err != nil {
+				return nil, fmt.Errorf("calendar source %s: %w", name, err)
+			}
+			sources = append(sources, multi.Source{Name: name, Provider: cal})
+		}
+		return multi.Provider{Sources: sources}, nil
+
+	case "file":
+		return file.Provider{Path: cfg.Calendar.File, Filter: calendarFilter(cfg.Calendar.Filter)}, nil
+
+	case "ics":
+		return ics.Provider{Path: cfg.Calendar.ICS}, nil
+
+	case "vcard":
+		return vcard.Provider{Path: cfg.Calendar.VCard}, nil
+
+	case "csv":
+		return csvProvider(cfg.Calendar.CSV), nil
+
+	case "caldav":
+		password, err := caldavPassword(ctx, cfg)
+		if err != nil {
+			return nil, err
+		}
+		return &caldav.Provider{
+			URL:          cfg.Calendar.CalDAV.URL,
+			Username:     cfg.Calendar.CalDAV.Username,
+			Password:     password,
+			CalendarName: cfg.Calendar.CalDAV.Calendar,
+			HTTPClient:   &http.Client{Timeout: 30 * time.Second},
+		}, nil
+
+	case "google":
+		secrets, err := buildSecretStore(cfg)
+		if err != nil {
+			return nil, err
+		}
+		svc, err := google.NewCalendarService(ctx, googleAuthConfig(cfg, secrets))
+		if err != nil {
+			return nil, err
+		}
+		return googleProvider(cfg, svc), nil
+
+	case "google-contacts":
+		secrets, err := buildSecretStore(cfg)
+		if err != nil {
+			return nil, err
+		}
+		svc, err := google.NewPeopleService(ctx, googleAuthConfig(cfg, secrets))
+		if err != nil {
+			return nil, err
+		}
+		return &google.ContactsProvider{
+			Svc:       svc,
+			StatePath: cfg.Calendar.Google.ContactsCache,
+		}, nil
+	}
+
+	return nil, fmt.Errorf("invalid calendar provider %q (use file|ics|vcard|csv|caldav|google|google-contacts|multi)", cfg.Calendar.Provider)
+}
+
+// runDate resolves --date against the configured timezone and schedule time.
+func runDate(cfg config.Config, dateStr string) (time.Time, error) {
+	loc, err := cfg.Location()
+	if err != nil {
+		return time.Time{}, err
+	}
+	hour, minute, err := cfg.ScheduleTime()
+	if err != nil {
+		return time.Time{}, err
+	}
+	return resolveRunDate(dateStr, loc, hour, minute)
+}
+
+func runClock(date time.Time, dateStr string) application.Clock {
+	return fixedOrSystemClock{fixed: date, system: clocksys.Clock{}, useFixed: dateStr != ""}
+}
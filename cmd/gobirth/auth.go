@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/rafakmp18/gobirth/internal/gobirth/adapters/calendar/google"
+)
+
+const authUsage = `usage: gobirth auth <command> [flags]
+
+commands:
+  google     authorize access to Google Calendar (or Contacts with
+             --calendar-provider=google-contacts) and store the token.
+             Google refuses these scopes to --google-auth=device; on a
+             headless machine use oauth over an SSH-forwarded port, or a
+             service account
+  revoke     revoke the stored Google token and delete it`
+
+func runAuth(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, authUsage)
+		return 2
+	}
+
+	switch args[0] {
+	case "google":
+		return runAuthGoogle(args[1:])
+	case "revoke":
+		return runAuthRevoke(args[1:])
+	default:
+		fmt.Fprintf(os.Stderr, "error: unknown auth command %q\n%s\n", args[0], authUsage)
+		return 2
+	}
+}
+
+func runAuthGoogle(args []string) int {
+	fs := newCommandFlags("gobirth auth google")
+	_ = fs.Parse(args)
+
+	cfg, err := loadConfig(*fs.configPath, fs.FlagSet)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		return 2
+	}
+
+	secrets, err := buildSecretStore(cfg)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		return 2
+	}
+
+	authCfg := googleAuthConfig(cfg, secrets)
+	if err := google.Authorize(context.Background(), authCfg); err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		return 1
+	}
+
+	what := "Google Calendar"
+	if cfg.Calendar.Provider == "google-contacts" {
+		what = "Google Contacts"
+	}
+	fmt.Printf("%s access authorized. Token stored in %s\n", what, google.TokenLocation(authCfg))
+	return 0
+}
+
+func runAuthRevoke(args []string) int {
+	fs := newCommandFlags("gobirth auth revoke")
+	_ = fs.Parse(args)
+
+	cfg, err := loadConfig(*fs.configPath, fs.FlagSet)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		return 2
+	}
+
+	secrets, err := buildSecretStore(cfg)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		return 2
+	}
+
+	authCfg := googleAuthConfig(cfg, secrets)
+	if err := google.RevokeToken(context.Background(), authCfg); err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		return 1
+	}
+
+	fmt.Printf("Google token revoked and removed from %s\n", google.TokenLocation(authCfg))
+	return 0
+}
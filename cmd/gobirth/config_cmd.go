@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+const configUsage = `usage: gobirth config <command> [flags]
+
+commands:
+  validate   load the layered configuration and report every problem found
+  show       print the effective configuration as YAML`
+
+func runConfig(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, configUsage)
+		return 2
+	}
+
+	fs := newCommandFlags("gobirth config " + args[0])
+	redacted := fs.Bool("redacted", true, "Hide secrets such as sender tokens (show only)")
+
+	switch args[0] {
+	case "validate", "show":
+	default:
+		fmt.Fprintf(os.Stderr, "error: unknown config command %q\n%s\n", args[0], configUsage)
+		return 2
+	}
+	_ = fs.Parse(args[1:])
+
+	cfg, err := loadConfig(*fs.configPath, fs.FlagSet)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		return 2
+	}
+
+	if args[0] == "show" {
+		if *redacted {
+			cfg = cfg.Redacted()
+		}
+		out, err := cfg.YAML()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "error:", err)
+			return 1
+		}
+		fmt.Print(string(out))
+		return 0
+	}
+
+	if err := validateConfig(cfg); err != nil {
+		fmt.Fprintln(os.Stderr, "configuration is invalid:\n"+err.Error())
+		return 1
+	}
+	fmt.Println("configuration is valid")
+	return 0
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/rafakmp18/gobirth/internal/gobirth/adapters/calendar/cache"
+	"github.com/rafakmp18/gobirth/internal/gobirth/adapters/calendar/namedays"
+	"github.com/rafakmp18/gobirth/internal/gobirth/application"
+)
+
+// runDaemon stays in the foreground and sends the greetings every day at
+// schedule.time, for service managers that prefer a long-running process
+// to a cron job. With calendar.cache enabled it also refreshes the cache in
+// the background, so a run that finds the calendar down is served recent
+// data.
+func runDaemon(args []string) int {
+	fs := newCommandFlags("gobirth daemon")
+	refresh := fs.Duration("refresh", 6*time.Hour, "How often the calendar cache is refreshed between runs (with calendar.cache.enabled)")
+
+	cfg, err := fs.load(args)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		return 2
+	}
+	loc, err := cfg.Location()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		return 2
+	}
+	hour, minute, err := cfg.ScheduleTime()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		return 2
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	cal, err := buildCalendar(ctx, cfg)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		return 2
+	}
+	token, err := senderToken(ctx, cfg)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		return 2
+	}
+
+	if c := calendarCache(cal); c != nil && *refresh > 0 {
+		go c.RefreshEvery(ctx, *refresh, func(err error) {
+			fmt.Fprintln(os.Stderr, "warning: refresh calendar cache:", err)
+		})
+	}
+
+	uc := buildRunDailyGreetings(cfg, cal, token)
+	for {
+		next := nextRun(time.Now().In(loc), hour, minute)
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return 0
+		case <-timer.C:
+		}
+
+		uc.Clock = fixedOrSystemClock{fixed: next, useFixed: true}
+		if err := printResult(os.Stdout, cfg.Output, uc.Run(ctx)); err != nil {
+			fmt.Fprintln(os.Stderr, "error: write report:", err)
+		}
+	}
+}
+
+// nextRun returns the first hour:minute after now, in now's location.
+func nextRun(now time.Time, hour, minute int) time.Time {
+	y, m, d := now.Date()
+	next := time.Date(y, m, d, hour, minute, 0, 0, now.Location())
+	if !next.After(now) {
+		next = time.Date(y, m, d+1, hour, minute, 0, 0, now.Location())
+	}
+	return next
+}
+
+// calendarCache finds the cache that buildCalendar wraps the calendar in,
+// if any.
+func calendarCache(cal application.CalendarProvider) *cache.Provider {
+	if n, ok := cal.(*namedays.Provider); ok {
+		cal = n.Upstream
+	}
+	c, _ := cal.(*cache.Provider)
+	return c
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"errors"
+
+	"github.com/rafakmp18/gobirth/internal/gobirth/adapters/calendar/google"
+	"github.com/rafakmp18/gobirth/internal/gobirth/application"
+	"github.com/rafakmp18/gobirth/internal/gobirth/domain"
+)
+
+// hintFor returns a short, actionable suggestion for a run error, or "" when
+// there is nothing more useful to say than the error itself.
+func hintFor(err error) string {
+	switch {
+	case errors.Is(err, domain.ErrMissingPhone):
+		return `add a line like "phone: +34600111222" to the event description`
+	case errors.Is(err, domain.ErrInvalidPhone):
+		return "write the phone in international format: a leading + and 7-15 digits, no spaces"
+	case errors.Is(err, domain.ErrMissingName):
+		return "set the event title to the contact's name"
+	case errors.Is(err, domain.ErrInvalidGroup):
+		return `write the group ID on one line without spaces, e.g. "group: 120363012345678901@g.us"`
+	case errors.Is(err, application.ErrGroupsUnsupported):
+		return "the configured sender cannot post in groups; remove the group line or switch sender"
+	case errors.Is(err, domain.ErrInvalidYear):
+		return `write the year the occasion started, e.g. "since: 2019"`
+	case errors.Is(err, google.ErrTokenRevoked):
+		return `run "gobirth auth google" to authorize again`
+	case errors.Is(err, google.ErrNoWriteAccess):
+		return `run "gobirth auth google --google-write-back=property" to grant write access`
+	}
+
+	switch application.StageOf(err) {
+	case application.StageFetch:
+		return "check the calendar provider settings (--calendar-provider, --calendar-file, Google credentials)"
+	case application.StageGenerate:
+		return "check the message generator configuration"
+	case application.StageSend:
+		return "check the sender configuration and network connectivity"
+	case application.StageRecord:
+		return `write-back needs write access: run "gobirth auth google" again, or unset calendar.google.write_back`
+	}
+
+	return ""
+}
@@ -0,0 +1,154 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/rafakmp18/gobirth/internal/gobirth/adapters/calendar/google"
+	"github.com/rafakmp18/gobirth/internal/gobirth/config"
+	"google.golang.org/api/calendar/v3"
+)
+
+const googleUsage = `usage: gobirth google <command> [flags]
+
+commands:
+  sync       update the local copy of the Google calendar and list the
+             birthdays added, edited or removed since the last sync
+  annotate   copy the phone, lang and context lines of every description
+             into gobirth.* event properties; previews the changes
+             unless --write is given`
+
+func runGoogle(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, googleUsage)
+		return 2
+	}
+
+	switch args[0] {
+	case "sync":
+		return runGoogleSync(args[1:])
+	case "annotate":
+		return runGoogleAnnotate(args[1:])
+	default:
+		fmt.Fprintf(os.Stderr, "error: unknown google command %q\n%s\n", args[0], googleUsage)
+		return 2
+	}
+}
+
+func runGoogleSync(args []string) int {
+	fs := newCommandFlags("gobirth google sync")
+
+	cfg, err := fs.load(args)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		return 2
+	}
+
+	ctx := context.Background()
+	svc, code := googleCalendarService(ctx, "sync", cfg, false)
+	if code != 0 {
+		return code
+	}
+
+	// The command syncs even when calendar.google.sync is off, so the first
+	// full download can be done ahead of the daily runs.
+	cfg.Calendar.Google.Sync = true
+	res, err := googleProvider(cfg, svc).Sync(ctx)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		if hint := hintFor(err); hint != "" {
+			fmt.Fprintln(os.Stderr, "hint:", hint)
+		}
+		return 1
+	}
+
+	kind := "Incremental"
+	if res.Full {
+		kind = "Full"
+	}
+	fmt.Printf("%s sync done, stored in %s\n", kind, cfg.Calendar.Google.SyncState)
+	printChanges("added", res.Added)
+	printChanges("edited", res.Updated)
+	printChanges("removed", res.Removed)
+	return 0
+}
+
+func runGoogleAnnotate(args []string) int {
+	fs := newCommandFlags("gobirth google annotate")
+	strip := fs.Bool("strip", false, "Remove the migrated lines from the descriptions")
+	// Annotating edits every event, so it previews unless asked to write,
+	// whatever dry_run says for the daily runs.
+	write := fs.Bool("write", false, "Write the properties instead of only listing them")
+
+	cfg, err := fs.load(args)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		return 2
+	}
+
+	ctx := context.Background()
+	svc, code := googleCalendarService(ctx, "annotate", cfg, true)
+	if code != 0 {
+		return code
+	}
+
+	done, err := googleProvider(cfg, svc).Annotate(ctx, google.AnnotateOptions{Strip: *strip, DryRun: !*write})
+	for _, a := range done {
+		keys := make([]string, 0, len(a.Fields))
+		for k := range a.Fields {
+			keys = append(keys, google.PropertyPrefix+k)
+		}
+		sort.Strings(keys)
+		fmt.Printf("%s: %s\n", a.Title, strings.Join(keys, ", "))
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		if hint := hintFor(err); hint != "" {
+			fmt.Fprintln(os.Stderr, "hint:", hint)
+		}
+		return 1
+	}
+
+	if !*write {
+		fmt.Printf("Would annotate %d event(s); run again with --write to apply.\n", len(done))
+		return 0
+	}
+	fmt.Printf("Annotated %d event(s).\n", len(done))
+	return 0
+}
+
+// googleCalendarService checks that the Google provider is configured and
+// builds its client, with write access when write is set.
+func googleCalendarService(ctx context.Context, command string, cfg config.Config, write bool) (*calendar.Service, int) {
+	if cfg.Calendar.Provider != "google" {
+		fmt.Fprintf(os.Stderr, "error: google %s needs calendar.provider=google, got %q\n", command, cfg.Calendar.Provider)
+		return nil, 2
+	}
+
+	secrets, err := buildSecretStore(cfg)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		return nil, 2
+	}
+
+	authCfg := googleAuthConfig(cfg, secrets)
+	if write {
+		authCfg.Scopes = []string{calendar.CalendarEventsScope}
+	}
+	svc, err := google.NewCalendarService(ctx, authCfg)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		return nil, 1
+	}
+	return svc, 0
+}
+
+func printChanges(label string, titles []string) {
+	if len(titles) == 0 {
+		return
+	}
+	fmt.Printf("%s (%d): %s\n", label, len(titles), strings.Join(titles, ", "))
+}
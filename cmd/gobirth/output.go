@@ -0,0 +1,186 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/rafakmp18/gobirth/internal/gobirth/application"
+)
+
+type runReportDTO struct {
+	Total   int             `json:"total"`
+	Sent    int             `json:"sent"`
+	Skipped int             `json:"skipped"`
+	Failed  int             `json:"failed"`
+	Errors  []errorDTO      `json:"errors,omitempty"`
+	Items   []itemReportDTO `json:"items"`
+
+	FromCache       bool       `json:"from_cache,omitempty"`
+	CacheAgeSeconds int64      `json:"cache_age_seconds,omitempty"`
+	Warnings        []errorDTO `json:"warnings,omitempty"`
+}
+
+type errorDTO struct {
+	Stage   string `json:"stage,omitempty"`
+	EventID string `json:"event_id,omitempty"`
+	Message string `json:"message"`
+	Hint    string `json:"hint,omitempty"`
+}
+
+type itemReportDTO struct {
+	EventID     string `json:"event_id"`
+	ContactName string `json:"contact_name"`
+	Phone       string `json:"phone"`
+	Group       string `json:"group,omitempty"`
+	Channel     string `json:"channel"`
+	Outcome     string `json:"outcome"`
+	Stage       string `json:"stage,omitempty"`
+	Message     string `json:"message,omitempty"`
+	Error       string `json:"error,omitempty"`
+	Attempts    int    `json:"attempts"`
+	DurationMs  int64  `json:"duration_ms"`
+}
+
+func validOutputFormat(format string) bool {
+	switch format {
+	case "text", "json", "table":
+		return true
+	}
+	return false
+}
+
+func printResult(w io.Writer, format string, res application.RunResult) error {
+	switch format {
+	case "json":
+		return printJSON(w, res)
+	case "table":
+		return printTable(w, res)
+	default:
+		return printText(w, res)
+	}
+}
+
+func printText(w io.Writer, res application.RunResult) error {
+	fmt.Fprintf(w, "Run finished. total=%d sent=%d skipped=%d failed=%d\n",
+		res.Total, res.Sent, res.Skipped, res.Failed)
+	printWarnings(w, res)
+
+	if len(res.Errors) == 0 {
+		return nil
+	}
+
+	fmt.Fprintln(w, "Errors:")
+	for _, e := range res.Errors {
+		fmt.Fprintf(w, "- %v\n", e)
+		if hint := hintFor(e); hint != "" {
+			fmt.Fprintf(w, "  hint: %s\n", hint)
+		}
+	}
+	return nil
+}
+
+func printTable(w io.Writer, res application.RunResult) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "EVENT\tNAME\tTO\tCHANNEL\tOUTCOME\tATTEMPTS\tDURATION\tERROR")
+	for _, item := range res.Items {
+		errStr := ""
+		if item.Err != nil {
+			errStr = item.Err.Error()
+		}
+		to := item.Phone
+		if item.Group != "" {
+			to = "group " + item.Group
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%d\t%s\t%s\n",
+			item.EventID, item.ContactName, to, item.Channel,
+			item.Outcome, item.Attempts, item.Duration.Round(time.Millisecond), errStr)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(w, "\ntotal=%d sent=%d skipped=%d failed=%d\n",
+		res.Total, res.Sent, res.Skipped, res.Failed)
+	printWarnings(w, res)
+
+	if len(res.Items) == 0 {
+		for _, e := range res.Errors {
+			fmt.Fprintf(w, "error: %v\n", e)
+		}
+	}
+	return nil
+}
+
+// printWarnings notes a run served from cache and any other warnings.
+func printWarnings(w io.Writer, res application.RunResult) {
+	if res.FromCache {
+		fmt.Fprintf(w, "Calendar unreachable: served from cache (age %s).\n", res.CacheAge.Round(time.Minute))
+	}
+	for _, e := range res.Warnings {
+		fmt.Fprintf(w, "warning: %v\n", e)
+		if hint := hintFor(e); hint != "" {
+			fmt.Fprintf(w, "  hint: %s\n", hint)
+		}
+	}
+}
+
+func printJSON(w io.Writer, res application.RunResult) error {
+	dto := runReportDTO{
+		Total:     res.Total,
+		Sent:      res.Sent,
+		Skipped:   res.Skipped,
+		Failed:    res.Failed,
+		Items:     make([]itemReportDTO, 0, len(res.Items)),
+		FromCache: res.FromCache,
+	}
+	if res.FromCache {
+		dto.CacheAgeSeconds = int64(res.CacheAge.Seconds())
+	}
+
+	for _, e := range res.Errors {
+		dto.Errors = append(dto.Errors, newErrorDTO(e))
+	}
+	for _, e := range res.Warnings {
+		dto.Warnings = append(dto.Warnings, newErrorDTO(e))
+	}
+
+	for _, item := range res.Items {
+		d := itemReportDTO{
+			EventID:     item.EventID,
+			ContactName: item.ContactName,
+			Phone:       item.Phone,
+			Group:       item.Group,
+			Channel:     item.Channel,
+			Outcome:     string(item.Outcome),
+			Message:     item.Message,
+			Attempts:    item.Attempts,
+			DurationMs:  item.Duration.Milliseconds(),
+		}
+		if item.Err != nil {
+			d.Error = item.Err.Error()
+			d.Stage = string(application.StageOf(item.Err))
+		}
+		dto.Items = append(dto.Items, d)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(dto)
+}
+
+func newErrorDTO(e error) errorDTO {
+	d := errorDTO{
+		Stage:   string(application.StageOf(e)),
+		Message: e.Error(),
+		Hint:    hintFor(e),
+	}
+	var evErr *application.EventError
+	if errors.As(e, &evErr) {
+		d.EventID = evErr.EventID
+	}
+	return d
+}
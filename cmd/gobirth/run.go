@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/rafakmp18/gobirth/internal/gobirth/adapters/calendar/cache"
+	"github.com/rafakmp18/gobirth/internal/gobirth/adapters/calendar/namedays"
+	"github.com/rafakmp18/gobirth/internal/gobirth/adapters/message/template"
+	"github.com/rafakmp18/gobirth/internal/gobirth/adapters/whatsapp/stdout"
+	"github.com/rafakmp18/gobirth/internal/gobirth/application"
+	"github.com/rafakmp18/gobirth/internal/gobirth/config"
+	"github.com/rafakmp18/gobirth/internal/gobirth/domain"
+)
+
+func runRun(args []string) int {
+	return runGreetings("run", args, false)
+}
+
+// runPreview behaves like run but always in dry-run mode, whatever the
+// configuration says.
+func runPreview(args []string) int {
+	return runGreetings("preview", args, true)
+}
+
+func runGreetings(name string, args []string, forceDryRun bool) int {
+	fs := newCommandFlags("gobirth " + name)
+	dateStr := fs.String("date", "", "Run for a specific date (YYYY-MM-DD). Defaults to today.")
+
+	cfg, err := fs.load(args)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		return 2
+	}
+	if forceDryRun {
+		cfg.DryRun = true
+	}
+
+	date, err := runDate(cfg, *dateStr)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		return 2
+	}
+
+	ctx := context.Background()
+	cal, err := buildCalendar(ctx, cfg)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		return 2
+	}
+
+	token, err := senderToken(ctx, cfg)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		return 2
+	}
+
+	uc := buildRunDailyGreetings(cfg, cal, token)
+	uc.Clock = runClock(date, *dateStr)
+
+	res := uc.Run(ctx)
+
+	if err := printResult(os.Stdout, cfg.Output, res); err != nil {
+		fmt.Fprintln(os.Stderr, "error: write report:", err)
+		return 1
+	}
+
+	if res.Failed > 0 {
+		return 1
+	}
+	return 0
+}
+
+func buildRunDailyGreetings(cfg config.Config, cal application.CalendarProvider, token string) application.RunDailyGreetings {
+	// Keep stdout clean for machine-readable reports.
+	previewOut := os.Stdout
+	if cfg.Output == "json" {
+		previewOut = os.Stderr
+	}
+
+	return application.RunDailyGreetings{
+		Calendar:  cal,
+		Parser:    application.EventParser{},
+		Generator: messageGenerator(cfg.Message),
+		Sender:    buildSender(cfg, token, previewOut),
+		Previewer: stdout.New(previewOut),
+		Recorder:  greetingRecorder(cfg, cal),
+		MaxPerRun: cfg.MaxPerRun,
+		DryRun:    cfg.DryRun,
+		Joint:     cfg.Message.Joint,
+	}
+}
+
+// buildSender returns the sender selected by sender.provider, given the
+// token from senderToken. Config validation rejects unknown providers, and
+// stdout, the only one so far, needs no token.
+func buildSender(cfg config.Config, token string, out io.Writer) application.WhatsAppSender {
+	return stdout.New(out)
+}
+
+// messageGenerator builds the template generator, keying the per-occasion
+// templates by their normalized occasion.
+func messageGenerator(c config.MessageConfig) template.Generator {
+	g := template.Generator{Emoji: c.Emoji, Template: c.Template, GroupTemplate: c.GroupTemplate}
+	for name, text := range c.Templates {
+		if g.Templates == nil {
+			g.Templates = map[domain.Occasion]string{}
+		}
+		g.Templates[domain.ParseOccasion(name)] = text
+	}
+	return g
+}
+
+// greetingRecorder returns the write-back of the calendar, looking through
+// the name days and the cache, when calendar.google.write_back is set.
+func greetingRecorder(cfg config.Config, cal application.CalendarProvider) application.GreetingRecorder {
+	if cfg.Calendar.Google.WriteBack == "" {
+		return nil
+	}
+	n, withNameDays := cal.(*namedays.Provider)
+	if withNameDays {
+		cal = n.Upstream
+	}
+	if c, ok := cal.(*cache.Provider); ok {
+		cal = c.Upstream
+	}
+	rec, ok := cal.(application.GreetingRecorder)
+	if !ok {
+		return nil
+	}
+	if withNameDays {
+		return namedays.Recorder{Next: rec}
+	}
+	return rec
+}
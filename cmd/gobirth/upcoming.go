@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/rafakmp18/gobirth/internal/gobirth/application"
+	"github.com/rafakmp18/gobirth/internal/gobirth/config"
+)
+
+func runUpcoming(args []string) int {
+	fs := newCommandFlags("gobirth upcoming")
+	days := fs.Int("days", 30, "Number of days to look ahead, today included")
+	fromStr := fs.String("from", "", "First day to list (YYYY-MM-DD). Defaults to today.")
+
+	cfg, err := fs.load(args)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		return 2
+	}
+
+	listings, _, code := listEvents(cfg, *fromStr, *days)
+	if code != 0 {
+		return code
+	}
+
+	if err := printListings(os.Stdout, cfg.Output, listings); err != nil {
+		fmt.Fprintln(os.Stderr, "error: write report:", err)
+		return 1
+	}
+	return 0
+}
+
+// listEvents fetches the listings of a range. When only some calendar
+// sources failed, or the events came from the cache, it warns on stderr and
+// returns them with degraded set.
+func listEvents(cfg config.Config, fromStr string, days int) (listings []application.EventListing, degraded bool, code int) {
+	from, err := runDate(cfg, fromStr)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		return nil, false, 2
+	}
+
+	ctx := context.Background()
+	cal, err := buildCalendar(ctx, cfg)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		return nil, false, 2
+	}
+
+	listings, err = application.ListEvents{
+		Calendar: cal,
+		Parser:   application.EventParser{},
+	}.Run(ctx, from, days)
+	if err != nil {
+		level := "error"
+		if listings != nil {
+			level = "warning"
+		}
+		fmt.Fprintf(os.Stderr, "%s: %v\n", level, err)
+		if hint := hintFor(err); hint != "" {
+			fmt.Fprintln(os.Stderr, "hint:", hint)
+		}
+		if listings == nil {
+			return nil, false, 1
+		}
+		return listings, true, 0
+	}
+
+	return listings, false, 0
+}
+
+type listingDTO struct {
+	Date        string `json:"date"`
+	EventID     string `json:"event_id"`
+	ContactName string `json:"contact_name"`
+	Phone       string `json:"phone"`
+	Group       string `json:"group,omitempty"`
+	Occasion    string `json:"occasion,omitempty"`
+	Years       int    `json:"years,omitempty"`
+	Error       string `json:"error,omitempty"`
+	Hint        string `json:"hint,omitempty"`
+}
+
+func printListings(w io.Writer, format string, listings []application.EventListing) error {
+	if format == "json" {
+		dtos := make([]listingDTO, 0, len(listings))
+		for _, l := range listings {
+			d := listingDTO{
+				Date:        l.Date.Format("2006-01-02"),
+				EventID:     l.EventID,
+				ContactName: l.ContactName,
+				Phone:       l.Phone,
+				Group:       l.Group,
+				Occasion:    string(l.Occasion),
+				Years:       l.Years,
+			}
+			if l.Err != nil {
+				d.Error = l.Err.Error()
+				d.Hint = hintFor(l.Err)
+			}
+			dtos = append(dtos, d)
+		}
+
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(dtos)
+	}
+
+	if len(listings) == 0 {
+		fmt.Fprintln(w, "No events found.")
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "DATE\tNAME\tOCCASION\tTO\tEVENT\tSTATUS")
+	for _, l := range listings {
+		status := "ok"
+		if l.Err != nil {
+			status = l.Err.Error()
+		}
+		occasion := string(l.Occasion)
+		if l.Years > 0 {
+			occasion = fmt.Sprintf("%s (%d)", occasion, l.Years)
+		}
+		to := l.Phone
+		if l.Group != "" {
+			to = strings.TrimSpace(to + " group " + l.Group)
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\n",
+			l.Date.Format("Mon 2006-01-02"), l.ContactName, occasion, to, l.EventID, status)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+// runValidate parses every event in the given range and reports those that
+// would fail on their birthday, so calendar data can be fixed ahead of time.
+func runValidate(args []string) int {
+	fs := newCommandFlags("gobirth validate")
+	days := fs.Int("days", 366, "Number of days to check, starting at --from")
+	fromStr := fs.String("from", "", "First day to check (YYYY-MM-DD). Defaults to today.")
+
+	cfg, err := fs.load(args)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "error:", err)
+		return 2
+	}
+
+	listings, degraded, code := listEvents(cfg, *fromStr, *days)
+	if code != 0 {
+		return code
+	}
+
+	// A failed source is a problem even if the others, or the cache, were
+	// fine.
+	problems := 0
+	if degraded {
+		problems++
+	}
+	for _, l := range listings {
+		if l.Err == nil {
+			continue
+		}
+		problems++
+		fmt.Printf("- %s: %v\n", l.Date.Format("2006-01-02"), l.Err)
+		if hint := hintFor(l.Err); hint != "" {
+			fmt.Printf("  hint: %s\n", hint)
+		}
+	}
+
+	fmt.Printf("Checked %d events over %d days: %d problem(s).\n", len(listings), *days, problems)
+	if problems > 0 {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,41 @@
+// Package atomicfile writes files that may hold secrets or personal data.
+package atomicfile
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// Write stores data at path with 0600 permissions, creating the directory
+// with 0700 if needed. The data goes to a temp file in the same directory
+// that is then renamed over path, so a crash never leaves a truncated file
+// behind.
+func Write(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+"-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if err := tmp.Chmod(0o600); err != nil {
+		tmp.Close()
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
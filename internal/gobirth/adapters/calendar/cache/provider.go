@@ -0,0 +1,209 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/rafakmp18/gobirth/internal/gobirth/adapters/atomicfile"
+	"github.com/rafakmp18/gobirth/internal/gobirth/application"
+)
+
+// DefaultWindow is how far ahead each successful fetch is cached.
+const DefaultWindow = 60 * 24 * time.Hour
+
+// Provider decorates a CalendarProvider with an on-disk copy of its last
+// successful fetch. Every query fetches Window days ahead from Upstream and
+// stores them, unless the range starts before today; when Upstream fails and
+// the cache covers the requested range, the cached events are returned with
+// an *application.StaleFetchError so the run still greets people and reports
+// how old the data was. Long-running processes such as gobirth daemon also
+// keep it fresh between runs with RefreshEvery.
+type Provider struct {
+	Upstream application.CalendarProvider
+	Path     string
+	// Window is how far ahead each fetch reaches. Defaults to DefaultWindow.
+	Window time.Duration
+	// Now returns the current time. Defaults to time.Now.
+	Now func() time.Time
+
+	mu sync.Mutex
+}
+
+type snapshot struct {
+	FetchedAt time.Time  `json:"fetched_at"`
+	From      time.Time  `json:"from"`
+	To        time.Time  `json:"to"`
+	Events    []eventDTO `json:"events"`
+}
+
+type eventDTO struct {
+	ID          string            `json:"id"`
+	Title       string            `json:"title"`
+	Description string            `json:"description"`
+	StartDate   time.Time         `json:"start_date"`
+	Metadata    map[string]string `json:"metadata,omitempty"`
+	Greeted     bool              `json:"greeted,omitempty"`
+	GreetedTo   []string          `json:"greeted_to,omitempty"`
+}
+
+func (p *Provider) EventsForDate(ctx context.Context, date time.Time) ([]application.CalendarEvent, error) {
+	start := startOfDay(date)
+	return p.EventsBetween(ctx, start, start.AddDate(0, 0, 1))
+}
+
+func (p *Provider) EventsBetween(ctx context.Context, from, to time.Time) ([]application.CalendarEvent, error) {
+	fetchTo := to
+	if w := from.Add(p.window()); w.After(fetchTo) {
+		fetchTo = w
+	}
+
+	events, err := application.EventsBetween(ctx, p.Upstream, from, fetchTo)
+	if err == nil {
+		// Only windows from today on are worth keeping: a look at the past
+		// ("run --date 2025-01-01") must not replace the days ahead
+		// that tomorrow's run may need. A cache that can't be written must
+		// not stop today's greetings.
+		if !from.Before(startOfDay(p.now().In(from.Location()))) {
+			_ = p.store(events, from, fetchTo)
+		}
+		return application.EventsIn(events, from, to), nil
+	}
+
+	// A partial fetch is still news; pass it on without caching it, so a
+	// source that is down does not erase its events from the cache.
+	var partial *application.PartialFetchError
+	if errors.As(err, &partial) {
+		return application.EventsIn(events, from, to), err
+	}
+
+	snap, cacheErr := p.load()
+	if cacheErr != nil || snap.From.After(from) || snap.To.Before(to) {
+		return nil, err
+	}
+
+	return application.EventsIn(snap.events(from.Location()), from, to), &application.StaleFetchError{
+		CachedAt: snap.FetchedAt,
+		Err:      err,
+	}
+}
+
+// Refresh fetches Window days from today and updates the cache.
+func (p *Provider) Refresh(ctx context.Context) error {
+	from := startOfDay(p.now())
+	to := from.Add(p.window())
+
+	events, err := application.EventsBetween(ctx, p.Upstream, from, to)
+	if err != nil {
+		return err
+	}
+	return p.store(events, from, to)
+}
+
+// RefreshEvery calls Refresh right away and then every interval until ctx
+// is done. Errors go to onError, which may be nil.
+func (p *Provider) RefreshEvery(ctx context.Context, interval time.Duration, onError func(error)) {
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	for {
+		if err := p.Refresh(ctx); err != nil && onError != nil && ctx.Err() == nil {
+			onError(err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
+
+// store replaces the cache with the events of [from, to).
+func (p *Provider) store(events []application.CalendarEvent, from, to time.Time) error {
+	snap := snapshot{FetchedAt: p.now(), From: from, To: to}
+	for _, ev := range events {
+		snap.Events = append(snap.Events, eventDTO{
+			ID:          ev.ID,
+			Title:       ev.Title,
+			Description: ev.Description,
+			StartDate:   ev.StartDate,
+			Metadata:    ev.Metadata,
+			Greeted:     ev.Greeted,
+			GreetedTo:   ev.GreetedTo,
+		})
+	}
+	return p.save(snap)
+}
+
+func (p *Provider) load() (snapshot, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	b, err := os.ReadFile(p.Path)
+	if err != nil {
+		return snapshot{}, fmt.Errorf("calendar cache: %w", err)
+	}
+
+	var snap snapshot
+	if err := json.Unmarshal(b, &snap); err != nil {
+		return snapshot{}, fmt.Errorf("calendar cache: decode %s: %w", p.Path, err)
+	}
+	return snap, nil
+}
+
+// save writes the snapshot atomically with 0600 permissions, since it holds
+// names and phone numbers.
+func (p *Provider) save(snap snapshot) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	b, err := json.Marshal(snap)
+	if err != nil {
+		return fmt.Errorf("calendar cache: encode: %w", err)
+	}
+
+	if err := atomicfile.Write(p.Path, b); err != nil {
+		return fmt.Errorf("calendar cache: write: %w", err)
+	}
+	return nil
+}
+
+func (s snapshot) events(loc *time.Location) []application.CalendarEvent {
+	out := make([]application.CalendarEvent, 0, len(s.Events))
+	for _, d := range s.Events {
+		out = append(out, application.CalendarEvent{
+			ID:          d.ID,
+			Title:       d.Title,
+			Description: d.Description,
+			StartDate:   d.StartDate.In(loc),
+			Metadata:    d.Metadata,
+			Greeted:     d.Greeted,
+			GreetedTo:   d.GreetedTo,
+		})
+	}
+	return out
+}
+
+func (p *Provider) window() time.Duration {
+	if p.Window > 0 {
+		return p.Window
+	}
+	return DefaultWindow
+}
+
+func (p *Provider) now() time.Time {
+	if p.Now != nil {
+		return p.Now()
+	}
+	return time.Now()
+}
+
+func startOfDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
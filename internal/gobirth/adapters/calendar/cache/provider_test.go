@@ -0,0 +1,158 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rafakmp18/gobirth/internal/gobirth/application"
+)
+
+type fakeUpstream struct {
+	events []application.CalendarEvent
+	err    error
+	calls  [][2]time.Time
+}
+
+func (f *fakeUpstream) EventsForDate(ctx context.Context, date time.Time) ([]application.CalendarEvent, error) {
+	return f.EventsBetween(ctx, date, date.AddDate(0, 0, 1))
+}
+
+func (f *fakeUpstream) EventsBetween(ctx context.Context, from, to time.Time) ([]application.CalendarEvent, error) {
+	f.calls = append(f.calls, [2]time.Time{from, to})
+	if f.err != nil {
+		return nil, f.err
+	}
+	var out []application.CalendarEvent
+	for _, ev := range f.events {
+		if !ev.StartDate.Before(from) && ev.StartDate.Before(to) {
+			out = append(out, ev)
+		}
+	}
+	return out, nil
+}
+
+func TestProvider_ServesCacheWhenUpstreamFails(t *testing.T) {
+	day := time.Date(2026, 1, 16, 0, 0, 0, 0, time.UTC)
+	fetchedAt := day.Add(9 * time.Hour)
+
+	up := &fakeUpstream{events: []application.CalendarEvent{
+		{ID: "1", Title: "Pepe", Description: "phone: +34600111222", StartDate: day},
+		{ID: "2", Title: "Ana", Description: "phone: +34600333444", StartDate: day.AddDate(0, 0, 10)},
+	}}
+	p := &Provider{
+		Upstream: up,
+		Path:     filepath.Join(t.TempDir(), "cache.json"),
+		Now:      func() time.Time { return fetchedAt },
+	}
+
+	events, err := p.EventsForDate(context.Background(), day)
+	if err != nil || len(events) != 1 || events[0].ID != "1" {
+		t.Fatalf("live fetch = %+v, %v", events, err)
+	}
+	if got := up.calls[0][1].Sub(up.calls[0][0]); got != DefaultWindow {
+		t.Errorf("fetched window = %v, want %v", got, DefaultWindow)
+	}
+
+	// Ten days later Google is down: Ana still gets her greeting.
+	boom := errors.New("google unreachable")
+	up.err = boom
+
+	events, err = p.EventsForDate(context.Background(), day.AddDate(0, 0, 10))
+
+	var stale *application.StaleFetchError
+	if !errors.As(err, &stale) || !errors.Is(err, boom) {
+		t.Fatalf("err = %v, want StaleFetchError wrapping the upstream error", err)
+	}
+	if !stale.CachedAt.Equal(fetchedAt) {
+		t.Errorf("CachedAt = %v, want %v", stale.CachedAt, fetchedAt)
+	}
+	if len(events) != 1 || events[0].ID != "2" {
+		t.Errorf("cached events = %+v", events)
+	}
+}
+
+func TestProvider_FailsWhenCacheDoesNotCoverRange(t *testing.T) {
+	day := time.Date(2026, 1, 16, 0, 0, 0, 0, time.UTC)
+	up := &fakeUpstream{}
+	p := &Provider{
+		Upstream: up,
+		Path:     filepath.Join(t.TempDir(), "cache.json"),
+		Window:   48 * time.Hour,
+		Now:      func() time.Time { return day },
+	}
+
+	// Nothing cached yet.
+	up.err = errors.New("offline")
+	if _, err := p.EventsForDate(context.Background(), day); err == nil || errors.As(err, new(*application.StaleFetchError)) {
+		t.Fatalf("err = %v, want the upstream error", err)
+	}
+
+	up.err = nil
+	if _, err := p.EventsForDate(context.Background(), day); err != nil {
+		t.Fatal(err)
+	}
+
+	// The cache holds two days; the third is beyond it.
+	up.err = errors.New("offline")
+	if _, err := p.EventsForDate(context.Background(), day.AddDate(0, 0, 2)); err == nil || errors.As(err, new(*application.StaleFetchError)) {
+		t.Fatalf("err = %v, want the upstream error", err)
+	}
+	if _, err := p.EventsForDate(context.Background(), day.AddDate(0, 0, 1)); !errors.As(err, new(*application.StaleFetchError)) {
+		t.Fatalf("err = %v, want StaleFetchError", err)
+	}
+}
+
+func TestProvider_RefreshEveryStopsWithContext(t *testing.T) {
+	up := &fakeUpstream{}
+	p := &Provider{Upstream: up, Path: filepath.Join(t.TempDir(), "cache.json")}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		p.RefreshEvery(ctx, time.Hour, nil)
+		close(done)
+	}()
+
+	// The first refresh happens right away, without waiting an interval.
+	var err error
+	for i := 0; i < 200; i++ {
+		if _, err = p.load(); err == nil {
+			break
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	cancel()
+	<-done
+
+	if err != nil {
+		t.Fatalf("expected the first refresh to write the cache: %v", err)
+	}
+}
+
+func TestProvider_PastRangeKeepsCache(t *testing.T) {
+	today := time.Date(2026, 1, 16, 0, 0, 0, 0, time.UTC)
+	up := &fakeUpstream{events: []application.CalendarEvent{
+		{ID: "1", Title: "Pepe", Description: "phone: +34600111222", StartDate: today.AddDate(0, 0, 1)},
+	}}
+	p := &Provider{
+		Upstream: up,
+		Path:     filepath.Join(t.TempDir(), "cache.json"),
+		Now:      func() time.Time { return today.Add(9 * time.Hour) },
+	}
+
+	if _, err := p.EventsForDate(context.Background(), today); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if _, err := p.EventsBetween(context.Background(), today.AddDate(-1, 0, 0), today.AddDate(-1, 0, 7)); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+
+	up.err = errors.New("offline")
+	events, err := p.EventsForDate(context.Background(), today.AddDate(0, 0, 1))
+	if !errors.As(err, new(*application.StaleFetchError)) || len(events) != 1 {
+		t.Fatalf("expected tomorrow's event from the cache, got %+v, %v", events, err)
+	}
+}
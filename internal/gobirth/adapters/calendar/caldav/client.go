@@ -0,0 +1,282 @@
+package caldav
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const maxRedirects = 5
+
+// client speaks the small subset of WebDAV/CalDAV needed for discovery and
+// calendar-query REPORTs.
+type client struct {
+	http *http.Client
+	// base is the configured server URL; credentials are only sent to its
+	// scheme and host, and to the https hosts trusted during discovery.
+	base     string
+	username string
+	password string
+
+	mu      sync.Mutex
+	trusted map[string]bool
+}
+
+type calendarInfo struct {
+	URL         string
+	DisplayName string
+}
+
+type multistatus struct {
+	Responses []response `xml:"DAV: response"`
+}
+
+type response struct {
+	Href     string     `xml:"DAV: href"`
+	Propstat []propstat `xml:"DAV: propstat"`
+}
+
+type propstat struct {
+	Status string `xml:"DAV: status"`
+	Prop   prop   `xml:"DAV: prop"`
+}
+
+type prop struct {
+	CurrentUserPrincipal hrefProp     `xml:"DAV: current-user-principal"`
+	CalendarHomeSet      hrefProp     `xml:"urn:ietf:params:xml:ns:caldav calendar-home-set"`
+	DisplayName          string       `xml:"DAV: displayname"`
+	ResourceType         resourceType `xml:"DAV: resourcetype"`
+	CalendarData         string       `xml:"urn:ietf:params:xml:ns:caldav calendar-data"`
+}
+
+type hrefProp struct {
+	Href string `xml:"DAV: href"`
+}
+
+type resourceType struct {
+	Calendar *struct{} `xml:"urn:ietf:params:xml:ns:caldav calendar"`
+}
+
+const propfindPrincipal = `<?xml version="1.0" encoding="utf-8"?>
+<d:propfind xmlns:d="DAV:"><d:prop><d:current-user-principal/></d:prop></d:propfind>`
+
+const propfindHome = `<?xml version="1.0" encoding="utf-8"?>
+<d:propfind xmlns:d="DAV:" xmlns:c="urn:ietf:params:xml:ns:caldav"><d:prop><c:calendar-home-set/></d:prop></d:propfind>`
+
+const propfindCalendars = `<?xml version="1.0" encoding="utf-8"?>
+<d:propfind xmlns:d="DAV:"><d:prop><d:displayname/><d:resourcetype/></d:prop></d:propfind>`
+
+const calendarQuery = `<?xml version="1.0" encoding="utf-8"?>
+<c:calendar-query xmlns:d="DAV:" xmlns:c="urn:ietf:params:xml:ns:caldav">
+  <d:prop><d:getetag/><c:calendar-data/></d:prop>
+  <c:filter>
+    <c:comp-filter name="VCALENDAR">
+      <c:comp-filter name="VEVENT">
+        <c:time-range start="%s" end="%s"/>
+      </c:comp-filter>
+    </c:comp-filter>
+  </c:filter>
+</c:calendar-query>`
+
+func (c *client) currentUserPrincipal(ctx context.Context, base string) (string, error) {
+	ms, final, err := c.do(ctx, "PROPFIND", base, "0", propfindPrincipal)
+	if err != nil {
+		return "", err
+	}
+
+	for _, r := range ms.Responses {
+		for _, ps := range r.okPropstats() {
+			if href := ps.Prop.CurrentUserPrincipal.Href; href != "" {
+				return c.discovered(final, href)
+			}
+		}
+	}
+	return "", errors.New("server did not report current-user-principal")
+}
+
+func (c *client) calendarHome(ctx context.Context, principal string) (string, error) {
+	ms, final, err := c.do(ctx, "PROPFIND", principal, "0", propfindHome)
+	if err != nil {
+		return "", err
+	}
+
+	for _, r := range ms.Responses {
+		for _, ps := range r.okPropstats() {
+			if href := ps.Prop.CalendarHomeSet.Href; href != "" {
+				return c.discovered(final, href)
+			}
+		}
+	}
+	return "", errors.New("server did not report calendar-home-set")
+}
+
+func (c *client) calendars(ctx context.Context, home string) ([]calendarInfo, error) {
+	ms, final, err := c.do(ctx, "PROPFIND", home, "1", propfindCalendars)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []calendarInfo
+	for _, r := range ms.Responses {
+		for _, ps := range r.okPropstats() {
+			if ps.Prop.ResourceType.Calendar == nil {
+				continue
+			}
+			u, err := c.discovered(final, r.Href)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, calendarInfo{URL: u, DisplayName: ps.Prop.DisplayName})
+		}
+	}
+	return out, nil
+}
+
+// calendarQuery returns the raw iCalendar documents of every event that
+// overlaps [from, to).
+func (c *client) calendarQuery(ctx context.Context, calURL string, from, to time.Time) ([]string, error) {
+	body := fmt.Sprintf(calendarQuery, from.UTC().Format("20060102T150405Z"), to.UTC().Format("20060102T150405Z"))
+
+	ms, _, err := c.do(ctx, "REPORT", calURL, "1", body)
+	if err != nil {
+		return nil, err
+	}
+
+	var docs []string
+	for _, r := range ms.Responses {
+		for _, ps := range r.okPropstats() {
+			if data := strings.TrimSpace(ps.Prop.CalendarData); data != "" {
+				docs = append(docs, data)
+			}
+		}
+	}
+	return docs, nil
+}
+
+// trusts reports whether u may receive the password: it has the scheme and
+// host of the configured URL, or it is an https host that a trusted server
+// named in its discovery replies, like iCloud, whose calendars live on
+// pNN-caldav.icloud.com. A redirect alone never extends the trust.
+func (c *client) trusts(u *url.URL) bool {
+	base, err := url.Parse(c.base)
+	if err != nil {
+		return false
+	}
+	if strings.EqualFold(base.Scheme, u.Scheme) && strings.EqualFold(base.Host, u.Host) {
+		return true
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return u.Scheme == "https" && c.trusted[strings.ToLower(u.Host)]
+}
+
+// discovered resolves an href from the reply to a request sent to final,
+// and trusts its host when final was trusted.
+func (c *client) discovered(final, href string) (string, error) {
+	ref, err := resolve(final, href)
+	if err != nil {
+		return "", err
+	}
+
+	from, err := url.Parse(final)
+	if err != nil || !c.trusts(from) {
+		return ref, nil
+	}
+	if u, err := url.Parse(ref); err == nil && u.Scheme == "https" {
+		c.mu.Lock()
+		if c.trusted == nil {
+			c.trusted = map[string]bool{}
+		}
+		c.trusted[strings.ToLower(u.Host)] = true
+		c.mu.Unlock()
+	}
+	return ref, nil
+}
+
+// do sends a WebDAV request and decodes the 207 Multi-Status reply. Redirects
+// are followed manually because net/http would turn PROPFIND into GET.
+func (c *client) do(ctx context.Context, method, target, depth, body string) (multistatus, string, error) {
+	hc := *c.http
+	hc.CheckRedirect = func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }
+
+	for i := 0; i <= maxRedirects; i++ {
+		req, err := http.NewRequestWithContext(ctx, method, target, bytes.NewBufferString(body))
+		if err != nil {
+			return multistatus{}, "", err
+		}
+		req.Header.Set("Content-Type", `application/xml; charset="utf-8"`)
+		req.Header.Set("Depth", depth)
+		if (c.username != "" || c.password != "") && c.trusts(req.URL) {
+			req.SetBasicAuth(c.username, c.password)
+		}
+
+		resp, err := hc.Do(req)
+		if err != nil {
+			return multistatus{}, "", err
+		}
+
+		switch {
+		case resp.StatusCode >= 300 && resp.StatusCode < 400:
+			loc := resp.Header.Get("Location")
+			resp.Body.Close()
+			if loc == "" {
+				return multistatus{}, "", fmt.Errorf("%s %s: redirect without Location", method, target)
+			}
+			next, err := resolve(target, loc)
+			if err != nil {
+				return multistatus{}, "", err
+			}
+			target = next
+			continue
+
+		case resp.StatusCode == http.StatusUnauthorized:
+			resp.Body.Close()
+			return multistatus{}, "", fmt.Errorf("%s %s: unauthorized, check username and password", method, target)
+
+		case resp.StatusCode != http.StatusMultiStatus:
+			resp.Body.Close()
+			return multistatus{}, "", fmt.Errorf("%s %s: unexpected status %s", method, target, resp.Status)
+		}
+
+		var ms multistatus
+		err = xml.NewDecoder(io.LimitReader(resp.Body, 32<<20)).Decode(&ms)
+		resp.Body.Close()
+		if err != nil {
+			return multistatus{}, "", fmt.Errorf("%s %s: decode multistatus: %w", method, target, err)
+		}
+		return ms, target, nil
+	}
+
+	return multistatus{}, "", fmt.Errorf("%s %s: too many redirects", method, target)
+}
+
+func (r response) okPropstats() []propstat {
+	var out []propstat
+	for _, ps := range r.Propstat {
+		if ps.Status == "" || strings.Contains(ps.Status, " 200") {
+			out = append(out, ps)
+		}
+	}
+	return out
+}
+
+func resolve(base, ref string) (string, error) {
+	b, err := url.Parse(base)
+	if err != nil {
+		return "", fmt.Errorf("invalid URL %q: %w", base, err)
+	}
+	r, err := url.Parse(strings.TrimSpace(ref))
+	if err != nil {
+		return "", fmt.Errorf("invalid href %q: %w", ref, err)
+	}
+	return b.ResolveReference(r).String(), nil
+}
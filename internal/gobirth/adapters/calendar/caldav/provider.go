@@ -0,0 +1,130 @@
+package caldav
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rafakmp18/gobirth/internal/gobirth/adapters/calendar/ics"
+	"github.com/rafakmp18/gobirth/internal/gobirth/application"
+)
+
+// PasswordSecretKey is the secret store key the CLI reads the CalDAV
+// password from when it is not set in the config.
+const PasswordSecretKey = "caldav-password"
+
+// Provider reads events from a CalDAV server (Nextcloud, Radicale, iCloud,
+// ...). It discovers the user's calendar home from URL and picks the
+// calendar whose display name matches CalendarName, like google.Provider.
+// The password is sent to the host of URL and to the https hosts the server
+// names while discovering the calendar, never to redirect targets alone.
+type Provider struct {
+	// URL is the server root or its /.well-known/caldav endpoint.
+	URL          string
+	Username     string
+	Password     string
+	CalendarName string
+	HTTPClient   *http.Client
+
+	mu             sync.Mutex
+	cachedCalendar string
+	cl             *client
+}
+
+func (p *Provider) EventsForDate(ctx context.Context, date time.Time) ([]application.CalendarEvent, error) {
+	start := startOfDay(date)
+	return p.EventsBetween(ctx, start, start.AddDate(0, 0, 1))
+}
+
+func (p *Provider) EventsBetween(ctx context.Context, from, to time.Time) ([]application.CalendarEvent, error) {
+	if strings.TrimSpace(p.URL) == "" {
+		return nil, fmt.Errorf("caldav calendar: URL is required")
+	}
+	if strings.TrimSpace(p.CalendarName) == "" {
+		return nil, fmt.Errorf("caldav calendar: CalendarName is required")
+	}
+
+	calURL, err := p.calendarURL(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	docs, err := p.client().calendarQuery(ctx, calURL, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("caldav calendar: calendar-query: %w", err)
+	}
+
+	cal := &ics.Calendar{}
+	for _, doc := range docs {
+		parsed, err := ics.Parse(strings.NewReader(doc))
+		if err != nil {
+			return nil, fmt.Errorf("caldav calendar: parse calendar data: %w", err)
+		}
+		cal.Merge(parsed)
+	}
+
+	return cal.EventsBetween(from, to), nil
+}
+
+func (p *Provider) calendarURL(ctx context.Context) (string, error) {
+	p.mu.Lock()
+	if p.cachedCalendar != "" {
+		u := p.cachedCalendar
+		p.mu.Unlock()
+		return u, nil
+	}
+	p.mu.Unlock()
+
+	c := p.client()
+
+	principal, err := c.currentUserPrincipal(ctx, p.URL)
+	if err != nil {
+		return "", fmt.Errorf("caldav calendar: discover principal: %w", err)
+	}
+
+	home, err := c.calendarHome(ctx, principal)
+	if err != nil {
+		return "", fmt.Errorf("caldav calendar: discover calendar home: %w", err)
+	}
+
+	calendars, err := c.calendars(ctx, home)
+	if err != nil {
+		return "", fmt.Errorf("caldav calendar: list calendars: %w", err)
+	}
+
+	name := strings.TrimSpace(p.CalendarName)
+	for _, cal := range calendars {
+		if strings.EqualFold(strings.TrimSpace(cal.DisplayName), name) {
+			p.mu.Lock()
+			p.cachedCalendar = cal.URL
+			p.mu.Unlock()
+			return cal.URL, nil
+		}
+	}
+
+	return "", fmt.Errorf("caldav calendar: calendar %q not found in %s", name, home)
+}
+
+// client is kept across calls, so the hosts trusted during discovery are
+// still trusted when the cached calendar is queried.
+func (p *Provider) client() *client {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.cl == nil {
+		hc := p.HTTPClient
+		if hc == nil {
+			hc = http.DefaultClient
+		}
+		p.cl = &client{http: hc, base: p.URL, username: p.Username, password: p.Password}
+	}
+	return p.cl
+}
+
+func startOfDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,260 @@
+package caldav
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+const birthdayICS = `BEGIN:VCALENDAR
+VERSION:2.0
+BEGIN:VEVENT
+UID:pepe@example
+SUMMARY:Pepe
+DESCRIPTION:phone: +34600111222\ncontext: colega del gym
+DTSTART;VALUE=DATE:19900116
+RRULE:FREQ=YEARLY
+END:VEVENT
+END:VCALENDAR`
+
+// fakeServer is a minimal CalDAV server: /.well-known/caldav redirects to
+// /dav/, which reports the principal, home set, two calendars and answers
+// calendar-query REPORTs on the birthdays calendar.
+func fakeServer(t *testing.T) (*httptest.Server, *[]string) {
+	t.Helper()
+
+	var reports []string
+	mux := http.NewServeMux()
+
+	auth := func(w http.ResponseWriter, r *http.Request) bool {
+		u, p, ok := r.BasicAuth()
+		if !ok || u != "ana" || p != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return false
+		}
+		return true
+	}
+
+	multi := func(w http.ResponseWriter, body string) {
+		w.Header().Set("Content-Type", "application/xml")
+		w.WriteHeader(http.StatusMultiStatus)
+		fmt.Fprintf(w, `<?xml version="1.0"?><d:multistatus xmlns:d="DAV:" xmlns:c="urn:ietf:params:xml:ns:caldav">%s</d:multistatus>`, body)
+	}
+
+	mux.HandleFunc("/.well-known/caldav", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/dav/", http.StatusMovedPermanently)
+	})
+
+	mux.HandleFunc("/dav/", func(w http.ResponseWriter, r *http.Request) {
+		if !auth(w, r) {
+			return
+		}
+		if r.Method != "PROPFIND" {
+			t.Errorf("%s /dav/: want PROPFIND", r.Method)
+		}
+		multi(w, `<d:response><d:href>/dav/</d:href><d:propstat><d:prop>
+			<d:current-user-principal><d:href>/dav/principals/ana/</d:href></d:current-user-principal>
+			</d:prop><d:status>HTTP/1.1 200 OK</d:status></d:propstat></d:response>`)
+	})
+
+	mux.HandleFunc("/dav/principals/ana/", func(w http.ResponseWriter, r *http.Request) {
+		if !auth(w, r) {
+			return
+		}
+		multi(w, `<d:response><d:href>/dav/principals/ana/</d:href><d:propstat><d:prop>
+			<c:calendar-home-set><d:href>/dav/calendars/ana/</d:href></c:calendar-home-set>
+			</d:prop><d:status>HTTP/1.1 200 OK</d:status></d:propstat></d:response>`)
+	})
+
+	mux.HandleFunc("/dav/calendars/ana/", func(w http.ResponseWriter, r *http.Request) {
+		if !auth(w, r) {
+			return
+		}
+		if r.Header.Get("Depth") != "1" {
+			t.Errorf("home PROPFIND Depth = %q, want 1", r.Header.Get("Depth"))
+		}
+		multi(w, `
+			<d:response><d:href>/dav/calendars/ana/</d:href><d:propstat><d:prop>
+				<d:resourcetype><d:collection/></d:resourcetype>
+			</d:prop><d:status>HTTP/1.1 200 OK</d:status></d:propstat></d:response>
+			<d:response><d:href>personal/</d:href><d:propstat><d:prop>
+				<d:displayname>Personal</d:displayname>
+				<d:resourcetype><d:collection/><c:calendar/></d:resourcetype>
+			</d:prop><d:status>HTTP/1.1 200 OK</d:status></d:propstat></d:response>
+			<d:response><d:href>/dav/calendars/ana/birthdays/</d:href><d:propstat><d:prop>
+				<d:displayname>Birthdays</d:displayname>
+				<d:resourcetype><d:collection/><c:calendar/></d:resourcetype>
+			</d:prop><d:status>HTTP/1.1 200 OK</d:status></d:propstat></d:response>`)
+	})
+
+	mux.HandleFunc("/dav/calendars/ana/birthdays/", func(w http.ResponseWriter, r *http.Request) {
+		if !auth(w, r) {
+			return
+		}
+		if r.Method != "REPORT" {
+			t.Errorf("%s birthdays: want REPORT", r.Method)
+		}
+		body, _ := io.ReadAll(r.Body)
+		reports = append(reports, string(body))
+
+		multi(w, `<d:response><d:href>/dav/calendars/ana/birthdays/pepe.ics</d:href><d:propstat><d:prop>
+			<d:getetag>"1"</d:getetag>
+			<c:calendar-data>`+birthdayICS+`</c:calendar-data>
+			</d:prop><d:status>HTTP/1.1 200 OK</d:status></d:propstat></d:response>`)
+	})
+
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv, &reports
+}
+
+func TestProvider_DiscoversCalendarAndExpandsEvents(t *testing.T) {
+	srv, reports := fakeServer(t)
+
+	p := &Provider{
+		URL:          srv.URL + "/.well-known/caldav",
+		Username:     "ana",
+		Password:     "secret",
+		CalendarName: "birthdays",
+	}
+
+	date := time.Date(2026, 1, 16, 9, 0, 0, 0, time.Local)
+	events, err := p.EventsForDate(context.Background(), date)
+	if err != nil {
+		t.Fatalf("EventsForDate: %v", err)
+	}
+
+	if len(events) != 1 {
+		t.Fatalf("got %d events, want 1: %+v", len(events), events)
+	}
+	if events[0].Title != "Pepe" || !strings.Contains(events[0].Description, "+34600111222") {
+		t.Errorf("unexpected event %+v", events[0])
+	}
+	if events[0].ID != "pepe@example_20260116" {
+		t.Errorf("ID = %q, want occurrence ID", events[0].ID)
+	}
+
+	if len(*reports) != 1 || !strings.Contains((*reports)[0], `<c:time-range start="`) {
+		t.Errorf("unexpected REPORT bodies: %v", *reports)
+	}
+
+	// The calendar URL is cached, so a second call goes straight to REPORT.
+	if _, err := p.EventsForDate(context.Background(), date.AddDate(0, 0, 1)); err != nil {
+		t.Fatalf("second EventsForDate: %v", err)
+	}
+	if len(*reports) != 2 {
+		t.Errorf("got %d REPORTs, want 2", len(*reports))
+	}
+}
+
+func TestProvider_UnknownCalendar(t *testing.T) {
+	srv, _ := fakeServer(t)
+
+	p := &Provider{URL: srv.URL + "/dav/", Username: "ana", Password: "secret", CalendarName: "Work"}
+
+	_, err := p.EventsForDate(context.Background(), time.Now())
+	if err == nil || !strings.Contains(err.Error(), `calendar "Work" not found`) {
+		t.Fatalf("err = %v, want not found", err)
+	}
+}
+
+func TestProvider_Unauthorized(t *testing.T) {
+	srv, _ := fakeServer(t)
+
+	p := &Provider{URL: srv.URL + "/dav/", Username: "ana", Password: "wrong", CalendarName: "Birthdays"}
+
+	_, err := p.EventsForDate(context.Background(), time.Now())
+	if err == nil || !strings.Contains(err.Error(), "unauthorized") {
+		t.Fatalf("err = %v, want unauthorized", err)
+	}
+}
+
+func TestProvider_RedirectToOtherHostGetsNoPassword(t *testing.T) {
+	var leaked bool
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, _, ok := r.BasicAuth(); ok {
+			leaked = true
+		}
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer other.Close()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, other.URL+"/dav/", http.StatusMovedPermanently)
+	}))
+	defer srv.Close()
+
+	p := &Provider{URL: srv.URL + "/.well-known/caldav", Username: "ana", Password: "secret", CalendarName: "Birthdays"}
+
+	if _, err := p.EventsForDate(context.Background(), time.Now()); err == nil {
+		t.Fatalf("expected an error from the other host, got nil")
+	}
+	if leaked {
+		t.Fatalf("expected no credentials sent to another host")
+	}
+}
+
+func TestProvider_TrustsCalendarHomeOnAnotherHost(t *testing.T) {
+	multi := func(w http.ResponseWriter, body string) {
+		w.Header().Set("Content-Type", "application/xml")
+		w.WriteHeader(http.StatusMultiStatus)
+		fmt.Fprintf(w, `<?xml version="1.0"?><d:multistatus xmlns:d="DAV:" xmlns:c="urn:ietf:params:xml:ns:caldav">%s</d:multistatus>`, body)
+	}
+	authorized := func(w http.ResponseWriter, r *http.Request) bool {
+		if u, p, ok := r.BasicAuth(); !ok || u != "ana" || p != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return false
+		}
+		return true
+	}
+
+	// Like iCloud, the home set lives on a partition host.
+	partition := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !authorized(w, r) {
+			return
+		}
+		if r.Method == "PROPFIND" {
+			multi(w, `<d:response><d:href>/ana/calendars/birthdays/</d:href><d:propstat><d:prop>
+				<d:displayname>Birthdays</d:displayname>
+				<d:resourcetype><d:collection/><c:calendar/></d:resourcetype>
+				</d:prop><d:status>HTTP/1.1 200 OK</d:status></d:propstat></d:response>`)
+			return
+		}
+		multi(w, `<d:response><d:href>/ana/calendars/birthdays/pepe.ics</d:href><d:propstat><d:prop>
+			<c:calendar-data>`+birthdayICS+`</c:calendar-data>
+			</d:prop><d:status>HTTP/1.1 200 OK</d:status></d:propstat></d:response>`)
+	}))
+	defer partition.Close()
+
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !authorized(w, r) {
+			return
+		}
+		multi(w, `<d:response><d:href>/</d:href><d:propstat><d:prop>
+			<d:current-user-principal><d:href>/ana/principal/</d:href></d:current-user-principal>
+			<c:calendar-home-set><d:href>`+partition.URL+`/ana/calendars/</d:href></c:calendar-home-set>
+			</d:prop><d:status>HTTP/1.1 200 OK</d:status></d:propstat></d:response>`)
+	}))
+	defer srv.Close()
+
+	p := &Provider{
+		URL:          srv.URL,
+		Username:     "ana",
+		Password:     "secret",
+		CalendarName: "Birthdays",
+		HTTPClient:   srv.Client(),
+	}
+
+	events, err := p.EventsForDate(context.Background(), time.Date(2026, 1, 16, 9, 0, 0, 0, time.Local))
+	if err != nil {
+		t.Fatalf("EventsForDate: %v", err)
+	}
+	if len(events) != 1 || events[0].Title != "Pepe" {
+		t.Fatalf("events = %+v", events)
+	}
+}
@@ -0,0 +1,113 @@
+// Package contentline reads the content lines shared by iCalendar (RFC 5545)
+// and vCard (RFC 6350) files: NAME;PARAM=VALUE:value.
+package contentline
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Property is one content line. Group prefixes (item1.TEL) are dropped from
+// Name, which is upper-cased like the parameter names.
+type Property struct {
+	Name   string
+	Params Params
+	Value  string
+}
+
+// Params holds parameter values split on commas, so TYPE=cell,voice and
+// TYPE=cell;TYPE=voice end up the same.
+type Params map[string][]string
+
+// Get returns the first value of key, or "".
+func (p Params) Get(key string) string {
+	if v := p[key]; len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
+
+// Has reports whether key has value, ignoring case.
+func (p Params) Has(key, value string) bool {
+	for _, v := range p[key] {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// Unfold joins folded lines (continuations start with a space or tab) and
+// drops empty ones.
+func Unfold(r io.Reader) ([]string, error) {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	var lines []string
+	for sc.Scan() {
+		line := strings.TrimRight(sc.Text(), "\r")
+		if line == "" {
+			continue
+		}
+		if (line[0] == ' ' || line[0] == '\t') && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	return lines, nil
+}
+
+// Parse splits an unfolded content line. Parameters without "=" are taken
+// as vCard 2.1 style bare types (TEL;CELL:...).
+func Parse(line string) (Property, error) {
+	inQuotes := false
+	colon := -1
+	for i, r := range line {
+		if r == '"' {
+			inQuotes = !inQuotes
+		}
+		if r == ':' && !inQuotes {
+			colon = i
+			break
+		}
+	}
+	if colon < 0 {
+		return Property{}, fmt.Errorf("missing ':' in %q", line)
+	}
+
+	head, value := line[:colon], line[colon+1:]
+	parts := strings.Split(head, ";")
+
+	name := strings.ToUpper(parts[0])
+	if i := strings.LastIndexByte(name, '.'); i >= 0 {
+		name = name[i+1:]
+	}
+
+	p := Property{Name: name, Params: Params{}, Value: value}
+	for _, param := range parts[1:] {
+		k, v, ok := strings.Cut(param, "=")
+		if !ok {
+			k, v = "TYPE", param
+		}
+		k = strings.ToUpper(k)
+		for _, item := range strings.Split(strings.Trim(v, `"`), ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				p.Params[k] = append(p.Params[k], item)
+			}
+		}
+	}
+	return p, nil
+}
+
+var unescaper = strings.NewReplacer(`\n`, "\n", `\N`, "\n", `\,`, ",", `\;`, ";", `\\`, `\`)
+
+// UnescapeText undoes the TEXT value escapes (\n, \, \; and \\).
+func UnescapeText(s string) string {
+	return unescaper.Replace(s)
+}
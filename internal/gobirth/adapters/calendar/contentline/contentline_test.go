@@ -0,0 +1,44 @@
+package contentline
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUnfold(t *testing.T) {
+	lines, err := Unfold(strings.NewReader("BEGIN:VCARD\r\nNOTE:one\r\n  two\r\n\r\n\tthree\r\nEND:VCARD\r\n"))
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if len(lines) != 3 || lines[1] != "NOTE:one twothree" {
+		t.Fatalf("expected the NOTE line unfolded, got %q", lines)
+	}
+}
+
+func TestParse(t *testing.T) {
+	p, err := Parse(`item1.tel;type="voice,cell";TYPE=pref;HOME:tel:+34600111222`)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if p.Name != "TEL" || p.Value != "tel:+34600111222" {
+		t.Fatalf("expected TEL with the tel: URI, got %q %q", p.Name, p.Value)
+	}
+	if got := p.Params["TYPE"]; len(got) != 4 || !p.Params.Has("TYPE", "CELL") || p.Params.Get("TYPE") != "voice" {
+		t.Fatalf("expected four TYPE values, got %q", got)
+	}
+
+	p, err = Parse(`DTSTART;TZID=Europe/Madrid:20260116T090000`)
+	if err != nil || p.Params.Get("TZID") != "Europe/Madrid" {
+		t.Fatalf("expected TZID Europe/Madrid, got %+v, %v", p, err)
+	}
+
+	if _, err := Parse("no colon here"); err == nil {
+		t.Fatalf("expected error for a line without ':', got nil")
+	}
+}
+
+func TestUnescapeText(t *testing.T) {
+	if got := UnescapeText(`gym\, k1\nBerlín\\`); got != "gym, k1\nBerlín\\" {
+		t.Fatalf("expected escapes undone, got %q", got)
+	}
+}
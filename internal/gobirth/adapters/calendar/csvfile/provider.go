@@ -0,0 +1,361 @@
+package csvfile
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rafakmp18/gobirth/internal/gobirth/application"
+	"github.com/rafakmp18/gobirth/internal/gobirth/domain"
+)
+
+// DefaultDateFormats are tried in order when Provider.DateFormats is empty.
+// Numeric dates are day-first, except the ISO forms 2006-01-02 and --01-02;
+// layouts without a year are yearly dates.
+var DefaultDateFormats = []string{
+	"2006-01-02",
+	"02/01/2006",
+	"2/1/2006",
+	"02.01.2006",
+	"20060102",
+	"--01-02",
+	"02/01",
+	"2/1",
+	"2 January 2006",
+	"January 2, 2006",
+	"2 Jan 2006",
+	"2 January",
+	"January 2",
+}
+
+// Columns maps each field to its header in the file. Matching ignores case
+// and surrounding spaces. Empty entries use the defaults from DefaultColumns;
+// Phone and Context are optional in the file.
+type Columns struct {
+	Name     string
+	Birthday string
+	Phone    string
+	Context  string
+}
+
+var DefaultColumns = Columns{
+	Name:     "name",
+	Birthday: "birthday",
+	Phone:    "phone",
+	Context:  "context",
+}
+
+// RowError reports an invalid row. Line is the 1-based line in the file.
+type RowError struct {
+	Line   int
+	Column string
+	Err    error
+}
+
+func (e *RowError) Error() string {
+	if e.Column == "" {
+		return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+	}
+	return fmt.Sprintf("line %d: %s: %v", e.Line, e.Column, e.Err)
+}
+
+func (e *RowError) Unwrap() error {
+	return e.Err
+}
+
+// Provider reads contacts from a CSV or TSV file with a header row, e.g. a
+// spreadsheet export, and emits a yearly event for each birthday. The
+// description carries phone and context in the format EventParser reads.
+// Invalid rows are skipped and reported, each with its line number, in an
+// *application.PartialFetchError next to the valid ones: those whose
+// birthday falls in the queried range, and all of them when the range spans
+// a year, as in "gobirth validate". A bad row thus fails the run of its
+// contact's birthday only, not every daily run.
+type Provider struct {
+	Path string
+	// Comma is the field delimiter. Defaults to a tab for .tsv files and a
+	// comma otherwise.
+	Comma       rune
+	Columns     Columns
+	DateFormats []string
+}
+
+type row struct {
+	line     int
+	name     string
+	birthday birthday
+	phone    string
+	context  string
+}
+
+// badRow is an invalid row; dated is set when its birthday could be read.
+type badRow struct {
+	errs     []error
+	birthday birthday
+	dated    bool
+}
+
+type birthday struct {
+	month time.Month
+	day   int
+}
+
+func (p Provider) EventsForDate(ctx context.Context, date time.Time) ([]application.CalendarEvent, error) {
+	start := startOfDay(date)
+	return p.EventsBetween(ctx, start, start.AddDate(0, 0, 1))
+}
+
+func (p Provider) EventsBetween(ctx context.Context, from, to time.Time) ([]application.CalendarEvent, error) {
+	_ = ctx
+
+	f, err := os.Open(p.Path)
+	if err != nil {
+		return nil, fmt.Errorf("csv calendar: open %s: %w", p.Path, err)
+	}
+	defer f.Close()
+
+	rows, bad, err := p.readRows(f)
+	if err != nil {
+		return nil, fmt.Errorf("csv calendar: %s: %w", p.Path, err)
+	}
+
+	var out []application.CalendarEvent
+	for _, r := range rows {
+		for _, occ := range r.birthday.between(from, to) {
+			out = append(out, r.event(occ))
+		}
+	}
+
+	sort.SliceStable(out, func(i, j int) bool {
+		return out[i].StartDate.Before(out[j].StartDate)
+	})
+
+	wholeYear := !to.Before(from.AddDate(1, 0, 0))
+	var errs []error
+	for _, b := range bad {
+		if !wholeYear && (!b.dated || len(b.birthday.between(from, to)) == 0) {
+			continue
+		}
+		for _, e := range b.errs {
+			errs = append(errs, fmt.Errorf("csv calendar: %s: %w", p.Path, e))
+		}
+	}
+	if len(errs) > 0 {
+		return out, &application.PartialFetchError{Errs: errs}
+	}
+	return out, nil
+}
+
+// readRows returns the valid rows and the invalid ones with their
+// RowErrors; err is set only when the file as a whole cannot be read.
+func (p Provider) readRows(r io.Reader) (rows []row, bad []badRow, err error) {
+	cr := csv.NewReader(r)
+	cr.Comma = p.comma()
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("read header: %w", err)
+	}
+
+	idx, err := p.columnIndexes(header)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for {
+		rec, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		line, _ := cr.FieldPos(0)
+		if err != nil {
+			var perr *csv.ParseError
+			if errors.As(err, &perr) {
+				bad = append(bad, badRow{errs: []error{&RowError{Line: perr.Line, Err: perr.Err}}})
+				continue
+			}
+			return nil, nil, err
+		}
+
+		if isBlank(rec) {
+			continue
+		}
+
+		r, dated, errs := p.parseRow(line, rec, idx)
+		if len(errs) > 0 {
+			bad = append(bad, badRow{errs: errs, birthday: r.birthday, dated: dated})
+			continue
+		}
+		rows = append(rows, r)
+	}
+	return rows, bad, nil
+}
+
+// columnIndexes resolves the configured headers to record positions; -1
+// marks an optional column that is absent.
+func (p Provider) columnIndexes(header []string) (map[string]int, error) {
+	cols := p.columns()
+
+	find := func(name string) int {
+		for i, h := range header {
+			h = strings.TrimPrefix(h, "\ufeff") // Excel's UTF-8 BOM
+			if strings.EqualFold(strings.TrimSpace(h), strings.TrimSpace(name)) {
+				return i
+			}
+		}
+		return -1
+	}
+
+	idx := map[string]int{
+		"name":     find(cols.Name),
+		"birthday": find(cols.Birthday),
+		"phone":    find(cols.Phone),
+		"context":  find(cols.Context),
+	}
+
+	var errs []error
+	if idx["name"] < 0 {
+		errs = append(errs, fmt.Errorf("header has no %q column", cols.Name))
+	}
+	if idx["birthday"] < 0 {
+		errs = append(errs, fmt.Errorf("header has no %q column", cols.Birthday))
+	}
+	return idx, errors.Join(errs...)
+}
+
+// parseRow reads one record; dated tells whether the birthday was valid.
+func (p Provider) parseRow(line int, rec []string, idx map[string]int) (r row, dated bool, errs []error) {
+	field := func(key string) string {
+		i := idx[key]
+		if i < 0 || i >= len(rec) {
+			return ""
+		}
+		return strings.TrimSpace(rec[i])
+	}
+
+	cols := p.columns()
+	r = row{
+		line:    line,
+		name:    field("name"),
+		phone:   domain.NormalizePhone(field("phone")),
+		context: field("context"),
+	}
+
+	if r.name == "" {
+		errs = append(errs, &RowError{Line: line, Column: cols.Name, Err: errors.New("missing name")})
+	}
+
+	raw := field("birthday")
+	if raw == "" {
+		errs = append(errs, &RowError{Line: line, Column: cols.Birthday, Err: errors.New("missing birthday")})
+	} else if b, err := parseBirthday(raw, p.dateFormats()); err != nil {
+		errs = append(errs, &RowError{Line: line, Column: cols.Birthday, Err: err})
+	} else {
+		r.birthday, dated = b, true
+	}
+
+	return r, dated, errs
+}
+
+func parseBirthday(value string, layouts []string) (birthday, error) {
+	for _, layout := range layouts {
+		// Year-less layouts parse into year 0, a leap year, so Feb 29 is
+		// accepted.
+		t, err := time.Parse(layout, value)
+		if err != nil {
+			continue
+		}
+		return birthday{month: t.Month(), day: t.Day()}, nil
+	}
+	return birthday{}, fmt.Errorf("unrecognised date %q", value)
+}
+
+// between returns the dates of the birthday in [from, to).
+func (b birthday) between(from, to time.Time) []time.Time {
+	var out []time.Time
+	for year := from.Year(); year <= to.Year(); year++ {
+		occ := domain.Anniversary(year, b.month, b.day, from.Location())
+		if !occ.Before(from) && occ.Before(to) {
+			out = append(out, occ)
+		}
+	}
+	return out
+}
+
+func (r row) event(occ time.Time) application.CalendarEvent {
+	var desc []string
+	if r.phone != "" {
+		desc = append(desc, "phone: "+r.phone)
+	}
+	if r.context != "" {
+		desc = append(desc, "context: "+r.context)
+	}
+
+	return application.CalendarEvent{
+		// The line tells apart two contacts with the same name.
+		ID:          fmt.Sprintf("%s_%d_%s", r.name, r.line, occ.Format("20060102")),
+		Title:       r.name,
+		Description: strings.Join(desc, "\n"),
+		StartDate:   occ,
+	}
+}
+
+func (p Provider) comma() rune {
+	if p.Comma != 0 {
+		return p.Comma
+	}
+	if strings.EqualFold(filepath.Ext(p.Path), ".tsv") {
+		return '\t'
+	}
+	return ','
+}
+
+func (p Provider) columns() Columns {
+	c := p.Columns
+	if c.Name == "" {
+		c.Name = DefaultColumns.Name
+	}
+	if c.Birthday == "" {
+		c.Birthday = DefaultColumns.Birthday
+	}
+	if c.Phone == "" {
+		c.Phone = DefaultColumns.Phone
+	}
+	if c.Context == "" {
+		c.Context = DefaultColumns.Context
+	}
+	return c
+}
+
+func (p Provider) dateFormats() []string {
+	if len(p.DateFormats) > 0 {
+		return p.DateFormats
+	}
+	return DefaultDateFormats
+}
+
+func isBlank(rec []string) bool {
+	for _, f := range rec {
+		if strings.TrimSpace(f) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+func startOfDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,147 @@
+package csvfile
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rafakmp18/gobirth/internal/gobirth/application"
+)
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestProvider_CSVWithDefaultColumns(t *testing.T) {
+	path := writeFile(t, "family.csv", "\ufeffName,Birthday,Phone,Context,Lang\n"+
+		"Pepe,16/01/1990,+34 600-111 222,\"colega del gym, y del k1\",es\n"+
+		"Ana,1985-01-16,+34600333444,,\n"+
+		"\n"+
+		"Luis,29/02,+34600555666,primo,en\n"+
+		"Marta,17 January,+34600777888,,\n")
+
+	p := Provider{Path: path}
+	events, err := p.EventsForDate(context.Background(), time.Date(2026, 1, 16, 9, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("EventsForDate: %v", err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("got %d events, want 2: %+v", len(events), events)
+	}
+	if events[0].ID != "Pepe_2_20260116" || events[0].Description != "phone: +34600111222\ncontext: colega del gym, y del k1" {
+		t.Errorf("pepe = %+v", events[0])
+	}
+	if events[1].Title != "Ana" || events[1].Description != "phone: +34600333444" {
+		t.Errorf("ana = %+v", events[1])
+	}
+
+	events, err = p.EventsForDate(context.Background(), time.Date(2026, 2, 28, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 || events[0].Title != "Luis" {
+		t.Errorf("Feb 28 2026 = %+v", events)
+	}
+}
+
+func TestProvider_TSVWithCustomColumns(t *testing.T) {
+	path := writeFile(t, "family.tsv", "Nombre\tCumple\tMóvil\tNotas\n"+
+		"Pepe\t01/16/1990\t+34600111222\tdel gym\n")
+
+	p := Provider{
+		Path:        path,
+		Columns:     Columns{Name: "nombre", Birthday: "cumple", Phone: "móvil", Context: "notas"},
+		DateFormats: []string{"01/02/2006"},
+	}
+	events, err := p.EventsForDate(context.Background(), time.Date(2026, 1, 16, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("EventsForDate: %v", err)
+	}
+	if len(events) != 1 || events[0].Description != "phone: +34600111222\ncontext: del gym" {
+		t.Fatalf("events = %+v", events)
+	}
+}
+
+func TestProvider_ReportsEveryInvalidRow(t *testing.T) {
+	path := writeFile(t, "family.csv", "name,birthday,phone\n"+
+		"Pepe,16/01/1990,+34600111222\n"+
+		",16/01/1990,+34600333444\n"+
+		"Ana,someday,+34600333444\n"+
+		"Luis,,+34600555666\n")
+	p := Provider{Path: path}
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	events, err := p.EventsBetween(context.Background(), from, from.AddDate(1, 0, 0))
+	var partial *application.PartialFetchError
+	if !errors.As(err, &partial) || len(partial.Errs) != 3 {
+		t.Fatalf("expected a partial error with 3 row errors over a year, got %v", err)
+	}
+	if len(events) != 1 || events[0].Title != "Pepe" {
+		t.Fatalf("expected the valid row to still be returned, got %+v", events)
+	}
+
+	for _, want := range []string{
+		"line 3: name: missing name",
+		`line 4: birthday: unrecognised date "someday"`,
+		"line 5: birthday: missing birthday",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q does not mention %q", err, want)
+		}
+	}
+
+	var rowErr *RowError
+	if !errors.As(err, &rowErr) || rowErr.Line != 3 {
+		t.Errorf("errors.As RowError = %+v", rowErr)
+	}
+}
+
+func TestProvider_DailyQueryReportsOnlyRowsOfTheDay(t *testing.T) {
+	path := writeFile(t, "family.csv", "name,birthday,phone\n"+
+		"Pepe,16/01/1990,+34600111222\n"+
+		",16/01/1990,+34600333444\n"+
+		"Ana,someday,+34600333444\n")
+	p := Provider{Path: path}
+
+	_, err := p.EventsForDate(context.Background(), time.Date(2026, 1, 16, 0, 0, 0, 0, time.UTC))
+	var partial *application.PartialFetchError
+	if !errors.As(err, &partial) || len(partial.Errs) != 1 || !strings.Contains(err.Error(), "line 3") {
+		t.Fatalf("expected only the nameless row of Jan 16, got %v", err)
+	}
+
+	if _, err := p.EventsForDate(context.Background(), time.Date(2026, 1, 17, 0, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatalf("expected nil error on a day without bad rows, got %v", err)
+	}
+}
+
+func TestProvider_MissingRequiredColumn(t *testing.T) {
+	path := writeFile(t, "family.csv", "name,phone\nPepe,+34600111222\n")
+
+	_, err := Provider{Path: path}.EventsForDate(context.Background(), time.Now())
+	if err == nil || !strings.Contains(err.Error(), `header has no "birthday" column`) {
+		t.Fatalf("err = %v", err)
+	}
+}
+
+func TestProvider_SameNameGetsDistinctIDs(t *testing.T) {
+	path := writeFile(t, "family.csv", "name,birthday,phone\n"+
+		"Pepe,16/01/1990,+34600111222\n"+
+		"Pepe,16/01/1960,+34600999888\n")
+
+	events, err := Provider{Path: path}.EventsForDate(context.Background(), time.Date(2026, 1, 16, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if len(events) != 2 || events[0].ID == events[1].ID {
+		t.Fatalf("expected two events with distinct IDs, got %+v", events)
+	}
+}
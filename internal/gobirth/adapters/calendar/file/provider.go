@@ -8,21 +8,41 @@ import (
 	"os"
 	"time"
 
+	"github.com/rafakmp18/gobirth/internal/gobirth/adapters/calendar/filter"
 	"github.com/rafakmp18/gobirth/internal/gobirth/application"
 )
 
 type Provider struct {
 	Path string
+	// Filter drops the events that are not greetings. Optional.
+	Filter *filter.Filter
 }
 
+// eventDTO is one entry of the JSON file. start_date is a date for all-day
+// events or an RFC 3339 date-time for timed ones.
 type eventDTO struct {
 	ID          string `json:"id"`
 	Title       string `json:"title"`
 	Description string `json:"description"`
 	StartDate   string `json:"start_date"`
+	Color       string `json:"color,omitempty"`
 }
 
 func (p Provider) EventsForDate(ctx context.Context, date time.Time) ([]application.CalendarEvent, error) {
+	want := ymd(date)
+
+	return p.events(ctx, date.Location(), func(evDate time.Time) bool {
+		return ymd(evDate) == want
+	})
+}
+
+func (p Provider) EventsBetween(ctx context.Context, from, to time.Time) ([]application.CalendarEvent, error) {
+	return p.events(ctx, from.Location(), func(evDate time.Time) bool {
+		return !evDate.Before(from) && evDate.Before(to)
+	})
+}
+
+func (p Provider) events(ctx context.Context, loc *time.Location, match func(time.Time) bool) ([]application.CalendarEvent, error) {
 	_ = ctx
 
 	f, err := os.Open(p.Path)
@@ -36,16 +56,18 @@ func (p Provider) EventsForDate(ctx context.Context, date time.Time) ([]applicat
 		return nil, err
 	}
 
-	want := ymd(date)
 	out := make([]application.CalendarEvent, 0, len(dtos))
 
 	for _, d := range dtos {
-		evDate, err := time.ParseInLocation("2006-01-02", d.StartDate, date.Location())
+		evDate, allDay, err := parseStart(d.StartDate, loc)
 		if err != nil {
 			return nil, fmt.Errorf("file calendar: invalid start_date for id=%s: %w", d.ID, err)
 		}
 
-		if ymd(evDate) != want {
+		if !match(evDate) {
+			continue
+		}
+		if !p.Filter.Keep(filter.Event{Title: d.Title, Description: d.Description, Color: d.Color, AllDay: allDay}) {
 			continue
 		}
 
@@ -60,6 +82,14 @@ func (p Provider) EventsForDate(ctx context.Context, date time.Time) ([]applicat
 	return out, nil
 }
 
+func parseStart(s string, loc *time.Location) (t time.Time, allDay bool, err error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t.In(loc), false, nil
+	}
+	t, err = time.ParseInLocation("2006-01-02", s, loc)
+	return t, true, err
+}
+
 func decodeEvents(r io.Reader) ([]eventDTO, error) {
 	var dtos []eventDTO
 	if err := json.NewDecoder(r).Decode(&dtos); err != nil {
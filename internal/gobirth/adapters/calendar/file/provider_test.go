@@ -3,8 +3,11 @@ package file
 import (
 	"context"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
+
+	"github.com/rafakmp18/gobirth/internal/gobirth/adapters/calendar/filter"
 )
 
 func TestProvider_EventsForDate_FiltersByDate(t *testing.T) {
@@ -38,3 +41,63 @@ func TestProvider_EventsForDate_FiltersByDate(t *testing.T) {
 		t.Fatalf("expected Pepe, got %q", events[0].Title)
 	}
 }
+
+func TestProvider_EventsBetween_FiltersByRange(t *testing.T) {
+	tmp, err := os.CreateTemp("", "gobirth-events-*.json")
+	if err != nil {
+		t.Fatalf("create temp: %v", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	json := `[
+  {"id":"1","title":"Pepe","description":"phone: +34600111222","start_date":"2026-01-16"},
+  {"id":"2","title":"Ana","description":"phone: +34600333444","start_date":"2026-01-20"},
+  {"id":"3","title":"Luis","description":"phone: +34600555666","start_date":"2026-01-23"}
+]`
+	if _, err := tmp.WriteString(json); err != nil {
+		t.Fatalf("write temp: %v", err)
+	}
+	_ = tmp.Close()
+
+	p := Provider{Path: tmp.Name()}
+
+	from := time.Date(2026, 1, 16, 0, 0, 0, 0, time.UTC)
+	events, err := p.EventsBetween(context.Background(), from, from.AddDate(0, 0, 7))
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+
+	if len(events) != 2 {
+		t.Fatalf("expected 2 events, got %d", len(events))
+	}
+	if events[1].Title != "Ana" {
+		t.Fatalf("expected Ana, got %q", events[1].Title)
+	}
+}
+
+func TestProvider_AppliesFilter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.json")
+	json := `[
+  {"id":"1","title":"Pepe","description":"phone: +34600111222","start_date":"2026-01-16"},
+  {"id":"2","title":"Ana","description":"phone: +34600333444 #nogreet","start_date":"2026-01-16"},
+  {"id":"3","title":"Luis","description":"phone: +34600555666","start_date":"2026-01-16","color":"red"},
+  {"id":"4","title":"Dentista","description":"","start_date":"2026-01-16T10:00:00+01:00"}
+]`
+	if err := os.WriteFile(path, []byte(json), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	p := Provider{Path: path, Filter: &filter.Filter{
+		ExcludeTags:   []string{"nogreet"},
+		ExcludeColors: []string{"red"},
+		Kind:          filter.AllDay,
+	}}
+
+	events, err := p.EventsForDate(context.Background(), time.Date(2026, 1, 16, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if len(events) != 1 || events[0].Title != "Pepe" {
+		t.Fatalf("expected only Pepe, got %+v", events)
+	}
+}
@@ -0,0 +1,111 @@
+// Package filter decides which calendar events are greetings, so calendars
+// that also hold anniversaries or reminders can be narrowed down before the
+// events reach the use case.
+package filter
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Kind restricts events by their timing.
+type Kind string
+
+const (
+	AnyKind Kind = ""
+	AllDay  Kind = "all-day"
+	Timed   Kind = "timed"
+)
+
+// Event is what a Filter looks at. Color is the provider's color ID, e.g.
+// Google's "1".."11"; empty means the calendar default.
+type Event struct {
+	Title       string
+	Description string
+	Color       string
+	AllDay      bool
+}
+
+// Filter keeps an event when it passes every configured rule. Include rules
+// with no values accept everything; tags are hashtags in the description,
+// written without '#' and compared case-insensitively.
+type Filter struct {
+	IncludeColors []string
+	ExcludeColors []string
+	IncludeTags   []string
+	ExcludeTags   []string
+	IncludeTitle  *regexp.Regexp
+	ExcludeTitle  *regexp.Regexp
+	Kind          Kind
+}
+
+var hashtagRe = regexp.MustCompile(`#([\p{L}\p{N}_-]+)`)
+
+// Keep reports whether ev passes the filter. A nil filter keeps everything.
+func (f *Filter) Keep(ev Event) bool {
+	if f == nil {
+		return true
+	}
+
+	switch f.Kind {
+	case AllDay:
+		if !ev.AllDay {
+			return false
+		}
+	case Timed:
+		if ev.AllDay {
+			return false
+		}
+	}
+
+	if len(f.IncludeColors) > 0 && !contains(f.IncludeColors, ev.Color) {
+		return false
+	}
+	if contains(f.ExcludeColors, ev.Color) {
+		return false
+	}
+
+	if f.IncludeTitle != nil && !f.IncludeTitle.MatchString(ev.Title) {
+		return false
+	}
+	if f.ExcludeTitle != nil && f.ExcludeTitle.MatchString(ev.Title) {
+		return false
+	}
+
+	if len(f.IncludeTags) > 0 || len(f.ExcludeTags) > 0 {
+		tags := hashtags(ev.Description)
+		if len(f.IncludeTags) > 0 && !anyIn(f.IncludeTags, tags) {
+			return false
+		}
+		if anyIn(f.ExcludeTags, tags) {
+			return false
+		}
+	}
+	return true
+}
+
+func hashtags(desc string) []string {
+	var out []string
+	for _, m := range hashtagRe.FindAllStringSubmatch(desc, -1) {
+		out = append(out, m[1])
+	}
+	return out
+}
+
+func contains(values []string, v string) bool {
+	for _, x := range values {
+		if strings.EqualFold(strings.TrimSpace(x), v) {
+			return true
+		}
+	}
+	return false
+}
+
+func anyIn(want, have []string) bool {
+	for _, h := range have {
+		if contains(want, h) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,59 @@
+package filter
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestFilter_Keep(t *testing.T) {
+	f := &Filter{
+		ExcludeColors: []string{"11"},
+		ExcludeTags:   []string{"nogreet"},
+		ExcludeTitle:  regexp.MustCompile(`(?i)aniversario|reminder`),
+		Kind:          AllDay,
+	}
+
+	cases := []struct {
+		name string
+		ev   Event
+		want bool
+	}{
+		{"birthday", Event{Title: "Pepe", Description: "phone: +34600111222", AllDay: true}, true},
+		{"timed", Event{Title: "Pepe", AllDay: false}, false},
+		{"red", Event{Title: "Pepe", Color: "11", AllDay: true}, false},
+		{"tagged", Event{Title: "Pepe", Description: "phone: +34600111222 #NoGreet", AllDay: true}, false},
+		{"other tag", Event{Title: "Pepe", Description: "#family", AllDay: true}, true},
+		{"title", Event{Title: "Aniversario boda", AllDay: true}, false},
+	}
+	for _, c := range cases {
+		if got := f.Keep(c.ev); got != c.want {
+			t.Errorf("%s: Keep = %v, want %v", c.name, got, c.want)
+		}
+	}
+}
+
+func TestFilter_IncludeRules(t *testing.T) {
+	f := &Filter{
+		IncludeColors: []string{"2", "5"},
+		IncludeTags:   []string{"cumple"},
+		IncludeTitle:  regexp.MustCompile(`^[A-Z]`),
+	}
+
+	if !f.Keep(Event{Title: "Pepe", Description: "#cumple", Color: "5"}) {
+		t.Error("expected an event matching every include rule to be kept")
+	}
+	if f.Keep(Event{Title: "Pepe", Description: "#cumple", Color: ""}) {
+		t.Error("expected the default color to fail IncludeColors")
+	}
+	if f.Keep(Event{Title: "Pepe", Description: "no tag", Color: "2"}) {
+		t.Error("expected a missing tag to fail IncludeTags")
+	}
+	if f.Keep(Event{Title: "pepe", Description: "#cumple", Color: "2"}) {
+		t.Error("expected the title pattern to apply")
+	}
+
+	var none *Filter
+	if !none.Keep(Event{}) {
+		t.Error("a nil filter keeps everything")
+	}
+}
@@ -0,0 +1,139 @@
+package google
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"google.golang.org/api/calendar/v3"
+	"google.golang.org/api/googleapi"
+
+	"github.com/rafakmp18/gobirth/internal/gobirth/application"
+)
+
+// PropertyPrefix namespaces the private extended properties gobirth reads
+// as contact metadata, e.g. "gobirth.phone" for application.MetaPhone.
+const PropertyPrefix = "gobirth."
+
+// ErrNoWriteAccess is returned by Annotate when Google refuses to edit an
+// event, usually because the token was granted read-only access.
+var ErrNoWriteAccess = errors.New("google calendar: no write access to the events")
+
+// AnnotateOptions controls Provider.Annotate.
+type AnnotateOptions struct {
+	// Strip removes the migrated lines from the description.
+	Strip bool
+	// DryRun reports the changes without writing them.
+	DryRun bool
+}
+
+// Annotation is the change made, or planned, on one event.
+type Annotation struct {
+	EventID string
+	Title   string
+	// Fields are the metadata keys written, without PropertyPrefix.
+	Fields map[string]string
+}
+
+// Annotate copies the "phone:", "lang:" and "context:" lines of every event
+// description into "gobirth.*" private extended properties, which the
+// provider then prefers over the description. Properties already set are
+// kept. It needs the calendar.events scope.
+func (p *Provider) Annotate(ctx context.Context, opts AnnotateOptions) ([]Annotation, error) {
+	if p.Svc == nil {
+		return nil, fmt.Errorf("google calendar: nil service")
+	}
+
+	calID, err := p.calendarID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []Annotation
+	var pageToken string
+	for {
+		call := p.Svc.Events.List(calID).Context(ctx).MaxResults(2500)
+		if pageToken != "" {
+			call = call.PageToken(pageToken)
+		}
+
+		events, err := call.Do()
+		if err != nil {
+			return out, fmt.Errorf("google calendar: events.list: %w", err)
+		}
+
+		for _, ev := range events.Items {
+			patch, ann, ok := annotation(ev, opts.Strip)
+			if !ok {
+				continue
+			}
+			if !opts.DryRun {
+				if _, err := p.Svc.Events.Patch(calID, ev.Id, patch).Context(ctx).Do(); err != nil {
+					var gerr *googleapi.Error
+					if errors.As(err, &gerr) && gerr.Code == http.StatusForbidden {
+						err = fmt.Errorf("%w: %w", ErrNoWriteAccess, err)
+					}
+					return out, fmt.Errorf("google calendar: events.patch %q: %w", ev.Summary, err)
+				}
+			}
+			out = append(out, ann)
+		}
+
+		if events.NextPageToken == "" {
+			break
+		}
+		pageToken = events.NextPageToken
+	}
+	return out, nil
+}
+
+// annotation builds the patch moving the description fields of ev into
+// properties; ok is false when there is nothing to migrate.
+func annotation(ev *calendar.Event, strip bool) (patch *calendar.Event, ann Annotation, ok bool) {
+	fields, rest := application.ParseDescription(ev.Description)
+
+	private := map[string]string{}
+	for k, v := range privateProperties(ev) {
+		private[k] = v
+	}
+
+	ann = Annotation{EventID: ev.Id, Title: ev.Summary, Fields: map[string]string{}}
+	for k, v := range fields {
+		if private[PropertyPrefix+k] != "" {
+			continue
+		}
+		private[PropertyPrefix+k] = v
+		ann.Fields[k] = v
+	}
+	// With strip, lines whose fields were migrated earlier still go.
+	stripped := strip && len(fields) > 0
+	if len(ann.Fields) == 0 && !stripped {
+		return nil, Annotation{}, false
+	}
+
+	patch = &calendar.Event{ExtendedProperties: &calendar.EventExtendedProperties{Private: private}}
+	if stripped {
+		patch.Description = rest
+		// An empty description must still be sent to clear the old one.
+		patch.ForceSendFields = []string{"Description"}
+	}
+	return patch, ann, true
+}
+
+// metadataOf returns the PropertyPrefix properties as CalendarEvent
+// metadata, leaving out the greeting marker.
+func metadataOf(private map[string]string) map[string]string {
+	var out map[string]string
+	for k, v := range private {
+		if k == GreetedProperty || !strings.HasPrefix(k, PropertyPrefix) {
+			continue
+		}
+		if out == nil {
+			out = map[string]string{}
+		}
+		out[strings.TrimPrefix(k, PropertyPrefix)] = v
+	}
+	return out
+}
@@ -0,0 +1,78 @@
+package google
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"google.golang.org/api/calendar/v3"
+
+	"github.com/rafakmp18/gobirth/internal/gobirth/application"
+)
+
+func TestProvider_AnnotateMigratesDescriptions(t *testing.T) {
+	f := &fakeEventsAPI{t: t, events: map[string]*calendar.Event{
+		"pepe": {
+			Id:          "pepe",
+			Summary:     "Pepe",
+			Description: "phone: +34600111222\nlang: es\ncontext: del gym\ny del k1",
+			Start:       &calendar.EventDateTime{Date: "2026-01-16"},
+		},
+		"ana": {
+			Id:                 "ana",
+			Summary:            "Ana",
+			Description:        "Cumple de Ana\nphone: +34600000000\ncontext: del curro",
+			Start:              &calendar.EventDateTime{Date: "2026-01-16"},
+			ExtendedProperties: &calendar.EventExtendedProperties{Private: map[string]string{"gobirth.phone": "+34600333444"}},
+		},
+		"luis": {Id: "luis", Summary: "Luis", Description: "sin datos", Start: &calendar.EventDateTime{Date: "2026-01-16"}},
+	}}
+	p := &Provider{Svc: newFakeEventsService(t, f), CalendarID: "cal"}
+
+	planned, err := p.Annotate(context.Background(), AnnotateOptions{Strip: true, DryRun: true})
+	if err != nil || len(planned) != 2 || f.patches != 0 {
+		t.Fatalf("dry run = %+v, %v, patches %d", planned, err, f.patches)
+	}
+
+	done, err := p.Annotate(context.Background(), AnnotateOptions{Strip: true})
+	if err != nil || len(done) != 2 || f.patches != 2 {
+		t.Fatalf("annotate = %+v, %v, patches %d", done, err, f.patches)
+	}
+
+	pepe := f.events["pepe"]
+	if pepe.Description != "" || pepe.ExtendedProperties.Private["gobirth.context"] != "del gym\ny del k1" ||
+		pepe.ExtendedProperties.Private["gobirth.lang"] != "es" {
+		t.Errorf("pepe = %q %v", pepe.Description, pepe.ExtendedProperties.Private)
+	}
+	ana := f.events["ana"]
+	if ana.Description != "Cumple de Ana" || ana.ExtendedProperties.Private["gobirth.phone"] != "+34600333444" {
+		t.Errorf("existing properties must win: ana = %q %v", ana.Description, ana.ExtendedProperties.Private)
+	}
+
+	// The provider now reads the contact data from the properties alone.
+	events, err := p.EventsForDate(context.Background(), time.Date(2026, 1, 16, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, ev := range events {
+		if ev.ID != "pepe" {
+			continue
+		}
+		c, err := application.EventParser{}.Parse(ev)
+		if err != nil || c.Phone().String() != "+34600111222" || c.Context() != "del gym\ny del k1" {
+			t.Errorf("parsed pepe = %+v, %v", c, err)
+		}
+	}
+}
+
+func TestProvider_AnnotateReportsMissingWriteAccess(t *testing.T) {
+	f := &fakeEventsAPI{t: t, readOnly: true, events: map[string]*calendar.Event{
+		"pepe": {Id: "pepe", Summary: "Pepe", Description: "phone: +34600111222", Start: &calendar.EventDateTime{Date: "2026-01-16"}},
+	}}
+	p := &Provider{Svc: newFakeEventsService(t, f), CalendarID: "cal"}
+
+	if _, err := p.Annotate(context.Background(), AnnotateOptions{}); !errors.Is(err, ErrNoWriteAccess) {
+		t.Fatalf("expected ErrNoWriteAccess, got %v", err)
+	}
+}
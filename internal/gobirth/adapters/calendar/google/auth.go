@@ -2,102 +2,198 @@ package google
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"os"
-	"path/filepath"
 
 	"golang.org/x/oauth2"
 	"golang.org/x/oauth2/google"
 	"google.golang.org/api/calendar/v3"
 	"google.golang.org/api/option"
+
+	"github.com/rafakmp18/gobirth/internal/gobirth/application"
+)
+
+type AuthMethod string
+
+const (
+	// AuthOAuth is the installed-app flow with a loopback redirect.
+	AuthOAuth AuthMethod = "oauth"
+	// AuthDevice is the device authorization grant for headless machines.
+	// Google does not grant the Calendar or Contacts scopes through it, so
+	// Authorize fails with a hint to use AuthOAuth or AuthServiceAccount.
+	AuthDevice AuthMethod = "device"
+	// AuthServiceAccount uses a service-account JSON key, optionally
+	// impersonating Subject through domain-wide delegation.
+	AuthServiceAccount AuthMethod = "service-account"
+	// AuthADC uses Application Default Credentials (GOOGLE_APPLICATION_CREDENTIALS,
+	// gcloud user credentials or the metadata server / workload identity).
+	AuthADC AuthMethod = "adc"
 )
 
 type AuthConfig struct {
 	CredentialsPath string
-	TokenPath       string
+	// TokenPath is the plain JSON token file, used when Secrets is nil.
+	TokenPath string
+	// Secrets stores the OAuth token under TokenSecretKey instead of
+	// TokenPath. Optional.
+	Secrets application.SecretStore
+	// TokenKey overrides TokenSecretKey, so tokens with different scopes can
+	// live in the same store. Optional.
+	TokenKey string
+	// Scopes requested for the token. Defaults to read-only Calendar access.
+	Scopes []string
+	// Method selects how gobirth authenticates. For the OAuth methods it also
+	// decides how Authorize obtains a token. Defaults to AuthOAuth.
+	Method AuthMethod
+
+	// ServiceAccountKeyPath is the JSON key used by AuthServiceAccount.
+	ServiceAccountKeyPath string
+	// Subject is the user to impersonate with AuthServiceAccount when the
+	// service account has domain-wide delegation. Optional.
+	Subject string
+
+	// Out receives the interactive authorization instructions. Defaults to
+	// os.Stdout.
+	Out io.Writer
+	// OpenBrowser opens the consent URL. Defaults to OpenBrowser; when it
+	// fails the URL is only printed.
+	OpenBrowser func(url string) error
 }
 
 func NewCalendarService(ctx context.Context, cfg AuthConfig) (*calendar.Service, error) {
-	b, err := os.ReadFile(cfg.CredentialsPath)
+	client, err := httpClient(ctx, cfg)
 	if err != nil {
-		return nil, fmt.Errorf("google auth: read credentials: %w", err)
+		return nil, err
 	}
 
-	oauthCfg, err := google.ConfigFromJSON(b, calendar.CalendarReadonlyScope)
+	svc, err := calendar.NewService(ctx, option.WithHTTPClient(client))
 	if err != nil {
-		return nil, fmt.Errorf("google auth: parse credentials json: %w", err)
+		return nil, fmt.Errorf("google auth: create calendar service: %w", err)
 	}
 
-	client, err := clientFromTokenCache(ctx, oauthCfg, cfg.TokenPath)
+	return svc, nil
+}
+
+func httpClient(ctx context.Context, cfg AuthConfig) (*http.Client, error) {
+	switch cfg.Method {
+	case AuthServiceAccount:
+		return serviceAccountClient(ctx, cfg)
+	case AuthADC:
+		return adcClient(ctx, cfg)
+	}
+
+	oauthCfg, err := oauthConfig(cfg)
 	if err != nil {
 		return nil, err
 	}
+	return clientFromTokenCache(ctx, oauthCfg, cfg)
+}
+
+func serviceAccountClient(ctx context.Context, cfg AuthConfig) (*http.Client, error) {
+	if cfg.ServiceAccountKeyPath == "" {
+		return nil, fmt.Errorf("google auth: service account key path is required")
+	}
 
-	svc, err := calendar.NewService(ctx, option.WithHTTPClient(client))
+	b, err := os.ReadFile(cfg.ServiceAccountKeyPath)
 	if err != nil {
-		return nil, fmt.Errorf("google auth: create calendar service: %w", err)
+		return nil, fmt.Errorf("google auth: read service account key: %w", err)
 	}
 
-	return svc, nil
+	jwtCfg, err := google.JWTConfigFromJSON(b, cfg.scopes()...)
+	if err != nil {
+		return nil, fmt.Errorf("google auth: parse service account key: %w", err)
+	}
+	jwtCfg.Subject = cfg.Subject
+
+	return jwtCfg.Client(ctx), nil
 }
 
-func clientFromTokenCache(ctx context.Context, config *oauth2.Config, tokenPath string) (*http.Client, error) {
-	tok, err := tokenFromFile(tokenPath)
+func adcClient(ctx context.Context, cfg AuthConfig) (*http.Client, error) {
+	creds, err := google.FindDefaultCredentials(ctx, cfg.scopes()...)
 	if err != nil {
-		tok, err = getTokenFromWeb(config)
-		if err != nil {
-			return nil, err
-		}
-		if err := saveToken(tokenPath, tok); err != nil {
-			return nil, err
-		}
-	}
-	return config.Client(ctx, tok), nil
+		return nil, fmt.Errorf("google auth: application default credentials: %w", err)
+	}
+	return oauth2.NewClient(ctx, creds.TokenSource), nil
 }
 
-func tokenFromFile(path string) (*oauth2.Token, error) {
-	f, err := os.Open(path)
+// Authorize runs the interactive flow selected by cfg.Method and stores the
+// new token, replacing any existing one.
+func Authorize(ctx context.Context, cfg AuthConfig) error {
+	if cfg.Method == AuthServiceAccount || cfg.Method == AuthADC {
+		return fmt.Errorf("google auth: %s authentication needs no interactive authorization", cfg.Method)
+	}
+
+	oauthCfg, err := oauthConfig(cfg)
 	if err != nil {
-		return nil, err
+		return err
 	}
-	defer f.Close()
 
-	var tok oauth2.Token
-	if err := json.NewDecoder(f).Decode(&tok); err != nil {
-		return nil, err
+	tok, err := getTokenFromWeb(ctx, oauthCfg, cfg)
+	if err != nil {
+		return err
 	}
-	return &tok, nil
+	return newTokenStore(cfg).Save(ctx, tok)
 }
 
-func saveToken(path string, token *oauth2.Token) error {
-	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
-		return fmt.Errorf("google auth: create token dir: %w", err)
+// TokenLocation describes where the OAuth token is stored, for messages.
+func TokenLocation(cfg AuthConfig) string {
+	return newTokenStore(cfg).Location()
+}
+
+func (cfg AuthConfig) scopes() []string {
+	if len(cfg.Scopes) == 0 {
+		return []string{calendar.CalendarReadonlyScope}
 	}
+	return cfg.Scopes
+}
 
-	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0o600)
+func oauthConfig(cfg AuthConfig) (*oauth2.Config, error) {
+	b, err := os.ReadFile(cfg.CredentialsPath)
 	if err != nil {
-		return fmt.Errorf("google auth: save token: %w", err)
+		return nil, fmt.Errorf("google auth: read credentials: %w", err)
 	}
-	defer f.Close()
 
-	return json.NewEncoder(f).Encode(token)
+	oauthCfg, err := google.ConfigFromJSON(b, cfg.scopes()...)
+	if err != nil {
+		return nil, fmt.Errorf("google auth: parse credentials json: %w", err)
+	}
+	if oauthCfg.Endpoint.DeviceAuthURL == "" {
+		oauthCfg.Endpoint.DeviceAuthURL = google.Endpoint.DeviceAuthURL
+	}
+	return oauthCfg, nil
 }
 
-func getTokenFromWeb(config *oauth2.Config) (*oauth2.Token, error) {
-	authURL := config.AuthCodeURL("state-token", oauth2.AccessTypeOffline)
-	fmt.Printf("GoBirth needs access to your Google Calendar.\nOpen this URL in your browser:\n\n%v\n\n", authURL)
+func clientFromTokenCache(ctx context.Context, config *oauth2.Config, cfg AuthConfig) (*http.Client, error) {
+	store := newTokenStore(cfg)
 
-	fmt.Print("Paste the authorization code here: ")
-	var code string
-	if _, err := fmt.Scan(&code); err != nil {
-		return nil, fmt.Errorf("google auth: read code: %w", err)
+	tok, err := store.Load(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("%w (%s: %v)", ErrNotAuthorized, store.Location(), err)
 	}
 
-	tok, err := config.Exchange(context.Background(), code)
-	if err != nil {
-		return nil, fmt.Errorf("google auth: exchange code for token: %w", err)
+	ts := newPersistingTokenSource(config.TokenSource(ctx, tok), store, tok)
+	return oauth2.NewClient(ctx, ts), nil
+}
+
+func getTokenFromWeb(ctx context.Context, config *oauth2.Config, cfg AuthConfig) (*oauth2.Token, error) {
+	out := cfg.Out
+	if out == nil {
+		out = os.Stdout
+	}
+
+	open := cfg.OpenBrowser
+	if open == nil {
+		open = OpenBrowser
+	}
+
+	switch cfg.Method {
+	case "", AuthOAuth:
+		return loopbackFlow{Out: out, OpenBrowser: open}.Token(ctx, config)
+	case AuthDevice:
+		return deviceFlow{Out: out}.Token(ctx, config)
+	default:
+		return nil, fmt.Errorf("google auth: unknown auth method %q", cfg.Method)
 	}
-	return tok, nil
 }
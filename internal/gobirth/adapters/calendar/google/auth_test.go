@@ -0,0 +1,121 @@
+package google
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestServiceAccountClient_RequestsTokenWithSubject(t *testing.T) {
+	var assertion string
+	tokenSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		if r.Form.Get("grant_type") != "urn:ietf:params:oauth:grant-type:jwt-bearer" {
+			t.Errorf("unexpected grant type %q", r.Form.Get("grant_type"))
+		}
+		assertion = r.Form.Get("assertion")
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"access_token": "sa-token", "token_type": "Bearer", "expires_in": 3600})
+	}))
+	defer tokenSrv.Close()
+
+	var gotAuth string
+	apiSrv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth = r.Header.Get("Authorization")
+	}))
+	defer apiSrv.Close()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	pemKey := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+
+	keyJSON, _ := json.Marshal(map[string]string{
+		"type":         "service_account",
+		"client_email": "gobirth@example.iam.gserviceaccount.com",
+		"private_key":  string(pemKey),
+		"token_uri":    tokenSrv.URL,
+	})
+	keyPath := filepath.Join(t.TempDir(), "sa.json")
+	if err := os.WriteFile(keyPath, keyJSON, 0o600); err != nil {
+		t.Fatalf("write key: %v", err)
+	}
+
+	client, err := httpClient(context.Background(), AuthConfig{
+		Method:                AuthServiceAccount,
+		ServiceAccountKeyPath: keyPath,
+		Subject:               "team@example.com",
+	})
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+
+	resp, err := client.Get(apiSrv.URL)
+	if err != nil {
+		t.Fatalf("request: %v", err)
+	}
+	resp.Body.Close()
+
+	if gotAuth != "Bearer sa-token" {
+		t.Fatalf("expected service account token, got %q", gotAuth)
+	}
+
+	parts := strings.Split(assertion, ".")
+	if len(parts) != 3 {
+		t.Fatalf("expected JWT assertion, got %q", assertion)
+	}
+	claims, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		t.Fatalf("decode claims: %v", err)
+	}
+	if !strings.Contains(string(claims), `"sub":"team@example.com"`) {
+		t.Fatalf("expected delegated subject in claims, got %s", claims)
+	}
+}
+
+func TestServiceAccountClient_RequiresKeyPath(t *testing.T) {
+	if _, err := httpClient(context.Background(), AuthConfig{Method: AuthServiceAccount}); err == nil {
+		t.Fatalf("expected error without key path")
+	}
+}
+
+func TestHTTPClient_MissingTokenAsksToAuthorize(t *testing.T) {
+	dir := t.TempDir()
+	creds := filepath.Join(dir, "credentials.json")
+	err := os.WriteFile(creds, []byte(`{"installed":{"client_id":"id","client_secret":"secret",
+		"auth_uri":"https://accounts.google.com/o/oauth2/auth","token_uri":"https://oauth2.googleapis.com/token",
+		"redirect_uris":["http://localhost"]}}`), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out strings.Builder
+	_, err = httpClient(context.Background(), AuthConfig{
+		CredentialsPath: creds,
+		TokenPath:       filepath.Join(dir, "token.json"),
+		Out:             &out,
+		OpenBrowser: func(string) error {
+			t.Error("a run must not start the interactive flow")
+			return nil
+		},
+	})
+	if !errors.Is(err, ErrNotAuthorized) {
+		t.Fatalf("err = %v, want ErrNotAuthorized", err)
+	}
+	if out.Len() != 0 {
+		t.Errorf("printed %q", out.String())
+	}
+}
@@ -0,0 +1,34 @@
+package google
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"runtime"
+)
+
+var errNoBrowser = errors.New("no browser available")
+
+// OpenBrowser tries to open url in the user's default browser. It returns an
+// error on headless machines so callers can fall back to printing the URL.
+func OpenBrowser(url string) error {
+	var cmd *exec.Cmd
+
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", url)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	default:
+		if os.Getenv("DISPLAY") == "" && os.Getenv("WAYLAND_DISPLAY") == "" {
+			return errNoBrowser
+		}
+		cmd = exec.Command("xdg-open", url)
+	}
+
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	go func() { _ = cmd.Wait() }()
+	return nil
+}
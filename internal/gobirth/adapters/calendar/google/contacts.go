@@ -0,0 +1,327 @@
+package google
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/api/googleapi"
+	"google.golang.org/api/option"
+	"google.golang.org/api/people/v1"
+
+	"github.com/rafakmp18/gobirth/internal/gobirth/adapters/atomicfile"
+	"github.com/rafakmp18/gobirth/internal/gobirth/application"
+	"github.com/rafakmp18/gobirth/internal/gobirth/domain"
+)
+
+// ContactsTokenSecretKey keeps the contacts token apart from the Calendar one
+// when AuthConfig.Secrets is set, because the two are granted different scopes.
+const ContactsTokenSecretKey = "google-contacts-token"
+
+const contactsPersonFields = "birthdays,phoneNumbers,names,biographies"
+
+// NewPeopleService builds a People API client with the contacts read-only
+// scope, using the same authentication methods as NewCalendarService.
+func NewPeopleService(ctx context.Context, cfg AuthConfig) (*people.Service, error) {
+	cfg.Scopes = []string{people.ContactsReadonlyScope}
+	if cfg.TokenKey == "" {
+		cfg.TokenKey = ContactsTokenSecretKey
+	}
+
+	client, err := httpClient(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	svc, err := people.NewService(ctx, option.WithHTTPClient(client))
+	if err != nil {
+		return nil, fmt.Errorf("google auth: create people service: %w", err)
+	}
+	return svc, nil
+}
+
+// ContactsProvider reads birthdays straight from Google Contacts. Each
+// contact with a birthday becomes a yearly event whose description carries
+// the preferred phone and the biography as context. Contacts are fetched
+// once and then kept up to date with People API sync tokens; with StatePath
+// set they survive between runs, so each run only downloads changes.
+type ContactsProvider struct {
+	Svc *people.Service
+	// StatePath is a JSON file holding the contacts and the sync token.
+	// Optional.
+	StatePath string
+
+	mu    sync.Mutex
+	state *contactsState
+}
+
+type contactsState struct {
+	SyncToken string                     `json:"sync_token"`
+	Contacts  map[string]contactBirthday `json:"contacts"`
+}
+
+type contactBirthday struct {
+	Name    string `json:"name"`
+	Phone   string `json:"phone,omitempty"`
+	Context string `json:"context,omitempty"`
+	Month   int    `json:"month"`
+	Day     int    `json:"day"`
+}
+
+func (p *ContactsProvider) EventsForDate(ctx context.Context, date time.Time) ([]application.CalendarEvent, error) {
+	start := startOfDay(date)
+	return p.EventsBetween(ctx, start, start.AddDate(0, 0, 1))
+}
+
+func (p *ContactsProvider) EventsBetween(ctx context.Context, from, to time.Time) ([]application.CalendarEvent, error) {
+	if p.Svc == nil {
+		return nil, fmt.Errorf("google contacts: nil service")
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err := p.sync(ctx); err != nil {
+		return nil, err
+	}
+
+	var out []application.CalendarEvent
+	for id, c := range p.state.Contacts {
+		for year := from.Year(); year <= to.Year(); year++ {
+			occ := domain.Anniversary(year, time.Month(c.Month), c.Day, from.Location())
+			if occ.Before(from) || !occ.Before(to) {
+				continue
+			}
+			out = append(out, c.event(id, occ))
+		}
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].StartDate.Equal(out[j].StartDate) {
+			return out[i].StartDate.Before(out[j].StartDate)
+		}
+		return out[i].ID < out[j].ID
+	})
+	return out, nil
+}
+
+// sync brings p.state up to date: a full listing the first time or when the
+// sync token expired, otherwise only the changes since the last call.
+func (p *ContactsProvider) sync(ctx context.Context) error {
+	if p.state == nil {
+		st, err := loadContactsState(p.StatePath)
+		if err != nil {
+			return err
+		}
+		p.state = st
+	}
+
+	err := p.list(ctx, p.state.SyncToken)
+	if p.state.SyncToken != "" && isExpiredSyncToken(err) {
+		p.state = &contactsState{Contacts: map[string]contactBirthday{}}
+		err = p.list(ctx, "")
+	}
+	if err != nil {
+		return err
+	}
+
+	return saveContactsState(p.StatePath, p.state)
+}
+
+func (p *ContactsProvider) list(ctx context.Context, syncToken string) error {
+	full := syncToken == ""
+	seen := map[string]contactBirthday{}
+
+	var pageToken string
+	for {
+		call := p.Svc.People.Connections.List("people/me").
+			Context(ctx).
+			PersonFields(contactsPersonFields).
+			PageSize(1000).
+			RequestSyncToken(true)
+		if syncToken != "" {
+			call = call.SyncToken(syncToken)
+		}
+		if pageToken != "" {
+			call = call.PageToken(pageToken)
+		}
+
+		resp, err := call.Do()
+		if err != nil {
+			return fmt.Errorf("google contacts: connections.list: %w", err)
+		}
+
+		for _, person := range resp.Connections {
+			if person.Metadata != nil && person.Metadata.Deleted {
+				delete(p.state.Contacts, person.ResourceName)
+				continue
+			}
+
+			c, ok := contactFromPerson(person)
+			if !ok {
+				// The birthday may have been removed from the contact.
+				delete(p.state.Contacts, person.ResourceName)
+				continue
+			}
+			seen[person.ResourceName] = c
+			p.state.Contacts[person.ResourceName] = c
+		}
+
+		if resp.NextPageToken == "" {
+			p.state.SyncToken = resp.NextSyncToken
+			break
+		}
+		pageToken = resp.NextPageToken
+	}
+
+	if full {
+		p.state.Contacts = seen
+	}
+	return nil
+}
+
+func contactFromPerson(person *people.Person) (contactBirthday, bool) {
+	var date *people.Date
+	for _, b := range person.Birthdays {
+		if b.Date == nil || b.Date.Month == 0 || b.Date.Day == 0 {
+			continue
+		}
+		if date == nil || isPrimary(b.Metadata) {
+			date = b.Date
+		}
+	}
+	if date == nil {
+		return contactBirthday{}, false
+	}
+
+	c := contactBirthday{Month: int(date.Month), Day: int(date.Day)}
+
+	for _, n := range person.Names {
+		if c.Name == "" || isPrimary(n.Metadata) {
+			c.Name = n.DisplayName
+		}
+	}
+
+	c.Phone = preferredPhone(person.PhoneNumbers)
+
+	for _, b := range person.Biographies {
+		if c.Context == "" || isPrimary(b.Metadata) {
+			c.Context = strings.TrimSpace(b.Value)
+		}
+	}
+
+	return c, true
+}
+
+// preferredPhone picks the primary number, then a mobile one, then the first.
+// The E.164 canonical form is used when Google provides it; otherwise the
+// number as typed is normalized.
+func preferredPhone(numbers []*people.PhoneNumber) string {
+	var best *people.PhoneNumber
+	score := func(n *people.PhoneNumber) int {
+		s := 0
+		if isPrimary(n.Metadata) {
+			s += 2
+		}
+		if strings.EqualFold(n.Type, "mobile") {
+			s++
+		}
+		return s
+	}
+
+	for _, n := range numbers {
+		if best == nil || score(n) > score(best) {
+			best = n
+		}
+	}
+	if best == nil {
+		return ""
+	}
+	if best.CanonicalForm != "" {
+		return best.CanonicalForm
+	}
+	return domain.NormalizePhone(best.Value)
+}
+
+func isPrimary(m *people.FieldMetadata) bool {
+	return m != nil && m.Primary
+}
+
+func (c contactBirthday) event(resourceName string, occ time.Time) application.CalendarEvent {
+	var desc []string
+	if c.Phone != "" {
+		desc = append(desc, "phone: "+c.Phone)
+	}
+	if c.Context != "" {
+		desc = append(desc, "context: "+c.Context)
+	}
+
+	return application.CalendarEvent{
+		ID:          fmt.Sprintf("%s_%s", resourceName, occ.Format("20060102")),
+		Title:       c.Name,
+		Description: strings.Join(desc, "\n"),
+		StartDate:   occ,
+	}
+}
+
+// isExpiredSyncToken reports whether the People API rejected the sync token,
+// which happens about a week after it was issued.
+func isExpiredSyncToken(err error) bool {
+	var gerr *googleapi.Error
+	if !errors.As(err, &gerr) {
+		return false
+	}
+	if gerr.Code == http.StatusGone {
+		return true
+	}
+	return gerr.Code == http.StatusBadRequest &&
+		(strings.Contains(gerr.Body, "EXPIRED_SYNC_TOKEN") || strings.Contains(gerr.Message, "EXPIRED_SYNC_TOKEN"))
+}
+
+func loadContactsState(path string) (*contactsState, error) {
+	st := &contactsState{Contacts: map[string]contactBirthday{}}
+	if path == "" {
+		return st, nil
+	}
+
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return st, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("google contacts: read state: %w", err)
+	}
+
+	if err := json.Unmarshal(b, st); err != nil {
+		// A corrupt state only costs a full sync.
+		return &contactsState{Contacts: map[string]contactBirthday{}}, nil
+	}
+	if st.Contacts == nil {
+		st.Contacts = map[string]contactBirthday{}
+	}
+	return st, nil
+}
+
+// saveContactsState writes the state atomically with 0600 permissions; it
+// holds names and phone numbers.
+func saveContactsState(path string, st *contactsState) error {
+	if path == "" {
+		return nil
+	}
+
+	b, err := json.Marshal(st)
+	if err != nil {
+		return fmt.Errorf("google contacts: save state: %w", err)
+	}
+	if err := atomicfile.Write(path, b); err != nil {
+		return fmt.Errorf("google contacts: save state: %w", err)
+	}
+	return nil
+}
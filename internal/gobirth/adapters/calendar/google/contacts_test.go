@@ -0,0 +1,172 @@
+package google
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"google.golang.org/api/option"
+	"google.golang.org/api/people/v1"
+)
+
+// fakePeople serves connections.list: a full listing without a sync token,
+// the queued incremental responses with one, and 400 EXPIRED_SYNC_TOKEN for
+// the token "expired".
+type fakePeople struct {
+	t           *testing.T
+	full        []*people.Person
+	incremental []*people.Person
+	requests    []string
+}
+
+func (f *fakePeople) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	f.requests = append(f.requests, q.Get("syncToken"))
+
+	if q.Get("personFields") != contactsPersonFields {
+		f.t.Errorf("personFields = %q", q.Get("personFields"))
+	}
+
+	var resp people.ListConnectionsResponse
+	switch q.Get("syncToken") {
+	case "":
+		// Two pages to exercise pagination.
+		if q.Get("pageToken") == "" {
+			resp = people.ListConnectionsResponse{Connections: f.full[:1], NextPageToken: "p2"}
+		} else {
+			resp = people.ListConnectionsResponse{Connections: f.full[1:], NextSyncToken: "sync-1"}
+		}
+	case "expired":
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`{"error":{"code":400,"message":"Sync token is expired. Clear local cache and retry call without the sync token.","status":"FAILED_PRECONDITION","details":[{"@type":"type.googleapis.com/google.rpc.ErrorInfo","reason":"EXPIRED_SYNC_TOKEN"}]}}`))
+		return
+	default:
+		resp = people.ListConnectionsResponse{Connections: f.incremental, NextSyncToken: "sync-2"}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+func newFakePeopleService(t *testing.T, f *fakePeople) *people.Service {
+	t.Helper()
+	srv := httptest.NewServer(f)
+	t.Cleanup(srv.Close)
+
+	svc, err := people.NewService(context.Background(),
+		option.WithEndpoint(srv.URL+"/"),
+		option.WithHTTPClient(srv.Client()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return svc
+}
+
+func person(id, name string, month, day int64, phones ...*people.PhoneNumber) *people.Person {
+	return &people.Person{
+		ResourceName: id,
+		Names:        []*people.Name{{DisplayName: name}},
+		Birthdays:    []*people.Birthday{{Date: &people.Date{Month: month, Day: day}}},
+		PhoneNumbers: phones,
+	}
+}
+
+func TestContactsProvider_FullThenIncrementalSync(t *testing.T) {
+	pepe := person("people/c1", "Pepe", 1, 16,
+		&people.PhoneNumber{Value: "910 00 00 00", Type: "home"},
+		&people.PhoneNumber{Value: "600 11 12 22", CanonicalForm: "+34600111222", Type: "mobile"},
+	)
+	pepe.Biographies = []*people.Biography{{Value: "colega del gym"}}
+
+	f := &fakePeople{
+		t: t,
+		full: []*people.Person{
+			pepe,
+			person("people/c2", "Ana", 1, 16, &people.PhoneNumber{Value: "+34 (600) 333-444", Metadata: &people.FieldMetadata{Primary: true}}),
+			{ResourceName: "people/c3", Names: []*people.Name{{DisplayName: "No birthday"}}},
+		},
+		incremental: []*people.Person{
+			{ResourceName: "people/c2", Metadata: &people.PersonMetadata{Deleted: true}},
+			person("people/c4", "Luis", 1, 16, &people.PhoneNumber{Value: "+34600555666"}),
+		},
+	}
+
+	statePath := filepath.Join(t.TempDir(), "contacts.json")
+	p := &ContactsProvider{Svc: newFakePeopleService(t, f), StatePath: statePath}
+	date := time.Date(2026, 1, 16, 9, 0, 0, 0, time.UTC)
+
+	events, err := p.EventsForDate(context.Background(), date)
+	if err != nil {
+		t.Fatalf("first EventsForDate: %v", err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("got %d events, want 2: %+v", len(events), events)
+	}
+	if events[0].ID != "people/c1_20260116" || events[0].Title != "Pepe" ||
+		events[0].Description != "phone: +34600111222\ncontext: colega del gym" {
+		t.Errorf("pepe = %+v", events[0])
+	}
+	if events[1].Description != "phone: +34600333444" {
+		t.Errorf("ana = %+v", events[1])
+	}
+
+	// A fresh provider resumes from the persisted state and sync token.
+	p2 := &ContactsProvider{Svc: p.Svc, StatePath: statePath}
+	events, err = p2.EventsForDate(context.Background(), date)
+	if err != nil {
+		t.Fatalf("second EventsForDate: %v", err)
+	}
+
+	var names []string
+	for _, ev := range events {
+		names = append(names, ev.Title)
+	}
+	if len(names) != 2 || names[0] != "Pepe" || names[1] != "Luis" {
+		t.Errorf("after incremental sync got %v, want [Pepe Luis]", names)
+	}
+
+	if want := []string{"", "", "sync-1"}; len(f.requests) != 3 || f.requests[2] != want[2] {
+		t.Errorf("sync tokens sent = %q, want %q", f.requests, want)
+	}
+}
+
+func TestContactsProvider_ExpiredSyncTokenResyncs(t *testing.T) {
+	f := &fakePeople{
+		t:    t,
+		full: []*people.Person{person("people/c1", "Pepe", 3, 1), person("people/c2", "Ana", 2, 29)},
+	}
+
+	statePath := filepath.Join(t.TempDir(), "contacts.json")
+	stale := &contactsState{
+		SyncToken: "expired",
+		Contacts:  map[string]contactBirthday{"people/old": {Name: "Gone", Month: 2, Day: 28}},
+	}
+	if err := saveContactsState(statePath, stale); err != nil {
+		t.Fatal(err)
+	}
+
+	p := &ContactsProvider{Svc: newFakePeopleService(t, f), StatePath: statePath}
+	events, err := p.EventsForDate(context.Background(), time.Date(2027, 2, 28, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("EventsForDate: %v", err)
+	}
+
+	// Feb 29 falls on Feb 28 in 2027 and the stale contact is dropped.
+	if len(events) != 1 || events[0].Title != "Ana" {
+		t.Fatalf("events = %+v", events)
+	}
+
+	st, err := loadContactsState(statePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st.SyncToken != "sync-1" || len(st.Contacts) != 2 {
+		t.Errorf("state after resync = %+v", st)
+	}
+}
@@ -0,0 +1,79 @@
+package google
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"golang.org/x/oauth2"
+)
+
+// deviceFlow runs the OAuth 2.0 device authorization grant (RFC 8628) for
+// machines without a browser: the user enters a short code on another device
+// while oauth2 polls the token endpoint. Google only grants a few scopes this
+// way, and Calendar and Contacts are not among them; errDeviceScope tells the
+// user what to use instead.
+type deviceFlow struct {
+	Out        io.Writer
+	HTTPClient *http.Client
+}
+
+func (f deviceFlow) Token(ctx context.Context, config *oauth2.Config) (*oauth2.Token, error) {
+	if config.Endpoint.DeviceAuthURL == "" {
+		return nil, fmt.Errorf("google auth: credentials have no device authorization endpoint")
+	}
+
+	if f.HTTPClient != nil {
+		ctx = context.WithValue(ctx, oauth2.HTTPClient, f.HTTPClient)
+	}
+
+	da, err := config.DeviceAuth(ctx)
+	if isInvalidScope(err) {
+		return nil, errDeviceScope
+	}
+	if err != nil {
+		return nil, fmt.Errorf("google auth: request device code: %w", err)
+	}
+
+	verifyURL := da.VerificationURIComplete
+	if verifyURL == "" {
+		verifyURL = da.VerificationURI
+	}
+	fmt.Fprintf(f.out(), "GoBirth needs access to your Google Calendar.\nOn any device, open:\n\n  %s\n\nand enter the code: %s\n\nWaiting for authorization ...\n",
+		verifyURL, da.UserCode)
+
+	tok, err := config.DeviceAccessToken(ctx, da)
+	if err == nil {
+		return tok, nil
+	}
+
+	var rerr *oauth2.RetrieveError
+	switch {
+	case isInvalidScope(err):
+		return nil, errDeviceScope
+	case errors.As(err, &rerr) && rerr.ErrorCode == "access_denied":
+		return nil, fmt.Errorf("google auth: authorization denied by user")
+	case errors.As(err, &rerr) && rerr.ErrorCode == "expired_token", errors.Is(err, context.DeadlineExceeded):
+		return nil, fmt.Errorf("google auth: device code expired, run the command again")
+	}
+	return nil, fmt.Errorf("google auth: device token: %w", err)
+}
+
+// errDeviceScope is returned when Google refuses the requested scopes to
+// the device flow.
+var errDeviceScope = errors.New("google auth: Google does not allow the Calendar or Contacts scopes in the device flow; " +
+	"use auth: oauth (on a headless machine, forward the loopback port over SSH) or auth: service-account")
+
+func isInvalidScope(err error) bool {
+	var rerr *oauth2.RetrieveError
+	return errors.As(err, &rerr) && rerr.ErrorCode == "invalid_scope"
+}
+
+func (f deviceFlow) out() io.Writer {
+	if f.Out == nil {
+		return io.Discard
+	}
+	return f.Out
+}
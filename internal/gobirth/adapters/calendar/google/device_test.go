@@ -0,0 +1,136 @@
+package google
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"golang.org/x/oauth2"
+)
+
+func TestDeviceFlow_PollsUntilAuthorized(t *testing.T) {
+	var (
+		mu    sync.Mutex
+		polls int
+	)
+	replies := []string{"authorization_pending", ""}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/device/code", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"device_code":      "dev-code",
+			"user_code":        "ABCD-EFGH",
+			"verification_url": "https://www.google.com/device",
+			"expires_in":       60,
+			"interval":         1,
+		})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		if r.Form.Get("grant_type") != "urn:ietf:params:oauth:grant-type:device_code" || r.Form.Get("device_code") != "dev-code" {
+			t.Errorf("unexpected token request: %v", r.Form)
+		}
+
+		mu.Lock()
+		polls++
+		reply := replies[polls-1]
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		if reply != "" {
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(map[string]string{"error": reply})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"access_token":  "access",
+			"refresh_token": "refresh",
+			"token_type":    "Bearer",
+			"expires_in":    3600,
+		})
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	cfg := &oauth2.Config{
+		ClientID:     "id",
+		ClientSecret: "secret",
+		Endpoint: oauth2.Endpoint{
+			DeviceAuthURL: srv.URL + "/device/code",
+			TokenURL:      srv.URL + "/token",
+		},
+	}
+
+	var out bytes.Buffer
+	flow := deviceFlow{Out: &out}
+
+	tok, err := flow.Token(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if tok.AccessToken != "access" || tok.RefreshToken != "refresh" {
+		t.Fatalf("unexpected token: %+v", tok)
+	}
+
+	if !strings.Contains(out.String(), "ABCD-EFGH") || !strings.Contains(out.String(), "https://www.google.com/device") {
+		t.Fatalf("expected user code and URL in output, got:\n%s", out.String())
+	}
+
+	if polls != 2 {
+		t.Fatalf("expected 2 polls, got %d", polls)
+	}
+}
+
+func TestDeviceFlow_FailsWhenAccessDenied(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/device/code", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"device_code": "dev-code", "user_code": "X", "verification_uri": "https://example/device", "interval": 1,
+		})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]string{"error": "access_denied"})
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	cfg := &oauth2.Config{
+		ClientID: "id",
+		Endpoint: oauth2.Endpoint{DeviceAuthURL: srv.URL + "/device/code", TokenURL: srv.URL + "/token"},
+	}
+
+	_, err := deviceFlow{}.Token(context.Background(), cfg)
+	if err == nil || !strings.Contains(err.Error(), "denied") {
+		t.Fatalf("expected access denied error, got %v", err)
+	}
+}
+
+func TestDeviceFlow_ExplainsRefusedScopes(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		_ = json.NewEncoder(w).Encode(map[string]string{"error": "invalid_scope"})
+	}))
+	defer srv.Close()
+
+	cfg := &oauth2.Config{
+		ClientID: "id",
+		Endpoint: oauth2.Endpoint{DeviceAuthURL: srv.URL + "/device/code", TokenURL: srv.URL + "/token"},
+		Scopes:   []string{"https://www.googleapis.com/auth/calendar.readonly"},
+	}
+
+	_, err := deviceFlow{}.Token(context.Background(), cfg)
+	if !errors.Is(err, errDeviceScope) {
+		t.Fatalf("expected errDeviceScope, got %v", err)
+	}
+}
@@ -0,0 +1,41 @@
+package google
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	"google.golang.org/api/calendar/v3"
+
+	"github.com/rafakmp18/gobirth/internal/gobirth/adapters/calendar/filter"
+)
+
+func TestProvider_FiltersEvents(t *testing.T) {
+	day := &calendar.EventDateTime{Date: "2026-01-16"}
+	f := &fakeEventsAPI{t: t, events: map[string]*calendar.Event{
+		"pepe":     {Id: "pepe", Summary: "Pepe", Description: "phone: +34600111222", Start: day},
+		"boda":     {Id: "boda", Summary: "Aniversario boda", Start: day},
+		"red":      {Id: "red", Summary: "Ana", ColorId: "11", Start: day},
+		"nogreet":  {Id: "nogreet", Summary: "Luis", Description: "phone: +34600555666 #nogreet", Start: day},
+		"reminder": {Id: "reminder", Summary: "Marta", Start: &calendar.EventDateTime{DateTime: "2026-01-16T10:00:00Z"}},
+	}}
+	p := &Provider{
+		Svc:        newFakeEventsService(t, f),
+		CalendarID: "cal",
+		Filter: &filter.Filter{
+			ExcludeColors: []string{"11"},
+			ExcludeTags:   []string{"nogreet"},
+			ExcludeTitle:  regexp.MustCompile(`(?i)aniversario`),
+			Kind:          filter.AllDay,
+		},
+	}
+
+	events, err := p.EventsForDate(context.Background(), time.Date(2026, 1, 16, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 || events[0].ID != "pepe" {
+		t.Fatalf("events = %+v, want only pepe", events)
+	}
+}
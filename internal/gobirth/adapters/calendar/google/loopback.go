@@ -0,0 +1,136 @@
+package google
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+const loopbackTimeout = 5 * time.Minute
+
+// loopbackFlow runs the OAuth authorization code flow for installed apps:
+// it listens on a random 127.0.0.1 port, sends the user to the consent
+// screen and waits for Google to redirect back with the code. State and PKCE
+// protect the redirect from CSRF and code interception.
+type loopbackFlow struct {
+	Out         io.Writer
+	OpenBrowser func(url string) error
+	Timeout     time.Duration
+}
+
+type callbackResult struct {
+	code string
+	err  error
+}
+
+func (f loopbackFlow) Token(ctx context.Context, config *oauth2.Config) (*oauth2.Token, error) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("google auth: start loopback listener: %w", err)
+	}
+	defer ln.Close()
+
+	cfg := *config
+	cfg.RedirectURL = fmt.Sprintf("http://%s/callback", ln.Addr().String())
+
+	state, err := randomState()
+	if err != nil {
+		return nil, err
+	}
+	verifier := oauth2.GenerateVerifier()
+
+	results := make(chan callbackResult, 1)
+	srv := &http.Server{
+		Handler:           callbackHandler(state, results),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	go func() { _ = srv.Serve(ln) }()
+	defer srv.Close()
+
+	authURL := cfg.AuthCodeURL(state, oauth2.AccessTypeOffline, oauth2.S256ChallengeOption(verifier))
+
+	fmt.Fprintf(f.out(), "GoBirth needs access to your Google Calendar.\n")
+	if f.OpenBrowser == nil || f.OpenBrowser(authURL) != nil {
+		fmt.Fprintf(f.out(), "Open this URL in a browser on this machine:\n\n%v\n\n", authURL)
+	} else {
+		fmt.Fprintf(f.out(), "Your browser has been opened. If it didn't, visit:\n\n%v\n\n", authURL)
+	}
+	fmt.Fprintf(f.out(), "Waiting for authorization on %s ...\n", cfg.RedirectURL)
+
+	timeout := f.Timeout
+	if timeout <= 0 {
+		timeout = loopbackTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var res callbackResult
+	select {
+	case res = <-results:
+	case <-ctx.Done():
+		return nil, fmt.Errorf("google auth: waiting for authorization: %w", ctx.Err())
+	}
+	if res.err != nil {
+		return nil, res.err
+	}
+
+	tok, err := cfg.Exchange(ctx, res.code, oauth2.VerifierOption(verifier))
+	if err != nil {
+		return nil, fmt.Errorf("google auth: exchange code for token: %w", err)
+	}
+	return tok, nil
+}
+
+func (f loopbackFlow) out() io.Writer {
+	if f.Out == nil {
+		return io.Discard
+	}
+	return f.Out
+}
+
+func callbackHandler(state string, results chan<- callbackResult) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/callback", func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+
+		var res callbackResult
+		switch {
+		case q.Get("state") != state:
+			res.err = errors.New("google auth: state mismatch in redirect, possible CSRF attempt")
+		case q.Get("error") != "":
+			res.err = fmt.Errorf("google auth: authorization denied: %s", q.Get("error"))
+		case q.Get("code") == "":
+			res.err = errors.New("google auth: redirect without authorization code")
+		default:
+			res.code = q.Get("code")
+		}
+
+		if res.err != nil {
+			http.Error(w, "GoBirth authorization failed. You can close this window.", http.StatusBadRequest)
+		} else {
+			fmt.Fprintln(w, "GoBirth is authorized. You can close this window.")
+		}
+
+		select {
+		case results <- res:
+		default:
+		}
+	})
+	return mux
+}
+
+func randomState() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("google auth: generate state: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
@@ -0,0 +1,118 @@
+package google
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+// fakeTokenServer checks the PKCE verifier against the challenge sent in the
+// consent URL before issuing a token.
+func fakeTokenServer(t *testing.T, challenge *string) *httptest.Server {
+	t.Helper()
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("parse form: %v", err)
+		}
+		if r.Form.Get("code") != "the-code" {
+			http.Error(w, "bad code", http.StatusBadRequest)
+			return
+		}
+		if oauth2.S256ChallengeFromVerifier(r.Form.Get("code_verifier")) != *challenge {
+			http.Error(w, "bad verifier", http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"access_token":  "access",
+			"refresh_token": "refresh",
+			"token_type":    "Bearer",
+			"expires_in":    3600,
+		})
+	}))
+}
+
+// redirectingBrowser simulates the user consenting: it follows the consent
+// URL straight to the loopback redirect with the given code and state.
+func redirectingBrowser(t *testing.T, challenge *string, state func(string) string) func(string) error {
+	return func(authURL string) error {
+		u, err := url.Parse(authURL)
+		if err != nil {
+			return err
+		}
+		q := u.Query()
+		*challenge = q.Get("code_challenge")
+
+		if q.Get("code_challenge_method") != "S256" {
+			t.Errorf("expected S256 challenge method, got %q", q.Get("code_challenge_method"))
+		}
+		if !strings.HasPrefix(q.Get("redirect_uri"), "http://127.0.0.1:") {
+			t.Errorf("expected loopback redirect, got %q", q.Get("redirect_uri"))
+		}
+
+		go func() {
+			cb := q.Get("redirect_uri") + "?code=the-code&state=" + url.QueryEscape(state(q.Get("state")))
+			resp, err := http.Get(cb)
+			if err == nil {
+				_, _ = io.Copy(io.Discard, resp.Body)
+				resp.Body.Close()
+			}
+		}()
+		return nil
+	}
+}
+
+func TestLoopbackFlow_ExchangesCodeWithPKCE(t *testing.T) {
+	var challenge string
+	srv := fakeTokenServer(t, &challenge)
+	defer srv.Close()
+
+	cfg := &oauth2.Config{
+		ClientID: "id",
+		Endpoint: oauth2.Endpoint{AuthURL: "https://accounts.example/auth", TokenURL: srv.URL},
+	}
+
+	flow := loopbackFlow{
+		OpenBrowser: redirectingBrowser(t, &challenge, func(s string) string { return s }),
+		Timeout:     5 * time.Second,
+	}
+
+	tok, err := flow.Token(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if tok.AccessToken != "access" || tok.RefreshToken != "refresh" {
+		t.Fatalf("unexpected token: %+v", tok)
+	}
+}
+
+func TestLoopbackFlow_RejectsStateMismatch(t *testing.T) {
+	var challenge string
+	srv := fakeTokenServer(t, &challenge)
+	defer srv.Close()
+
+	cfg := &oauth2.Config{
+		ClientID: "id",
+		Endpoint: oauth2.Endpoint{AuthURL: "https://accounts.example/auth", TokenURL: srv.URL},
+	}
+
+	flow := loopbackFlow{
+		OpenBrowser: redirectingBrowser(t, &challenge, func(string) string { return "forged" }),
+		Timeout:     5 * time.Second,
+	}
+
+	_, err := flow.Token(context.Background(), cfg)
+	if err == nil || !strings.Contains(err.Error(), "state mismatch") {
+		t.Fatalf("expected state mismatch error, got %v", err)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/rafakmp18/gobirth/internal/gobirth/adapters/calendar/filter"
 	"github.com/rafakmp18/gobirth/internal/gobirth/application"
 	"google.golang.org/api/calendar/v3"
 )
@@ -14,17 +15,46 @@ import (
 type Provider struct {
 	Svc          *calendar.Service
 	CalendarName string
+	// CalendarID skips the lookup by CalendarName. Service accounts need it
+	// because shared calendars don't show up in their calendar list.
+	CalendarID string
+	// StatePath enables incremental sync: the calendar is downloaded once
+	// into this JSON file and later queries only fetch the changes. Optional.
+	StatePath string
+	// MaxAge lets queries made within MaxAge of the last sync answer from
+	// the local store without calling the API. Only used with StatePath.
+	MaxAge time.Duration
+	// WriteBack makes RecordGreeting annotate the event after its greeting
+	// was sent. It needs the calendar.events scope. Empty disables it.
+	WriteBack WriteBack
+	// Filter drops the events that are not greetings. Optional.
+	Filter *filter.Filter
 
 	mu          sync.Mutex
 	cachedCalID string
+
+	syncMu   sync.Mutex
+	state    *syncState
+	syncedAt time.Time
 }
 
 func (p *Provider) EventsForDate(ctx context.Context, date time.Time) ([]application.CalendarEvent, error) {
+	start := startOfDay(date)
+	end := start.Add(24 * time.Hour)
+
+	return p.EventsBetween(ctx, start, end)
+}
+
+func (p *Provider) EventsBetween(ctx context.Context, from, to time.Time) ([]application.CalendarEvent, error) {
 	if p.Svc == nil {
 		return nil, fmt.Errorf("google calendar: nil service")
 	}
-	if strings.TrimSpace(p.CalendarName) == "" {
-		return nil, fmt.Errorf("google calendar: CalendarName is required")
+	if strings.TrimSpace(p.CalendarName) == "" && strings.TrimSpace(p.CalendarID) == "" {
+		return nil, fmt.Errorf("google calendar: CalendarName or CalendarID is required")
+	}
+
+	if p.StatePath != "" {
+		return p.storedEventsBetween(ctx, from, to)
 	}
 
 	calID, err := p.calendarID(ctx)
@@ -32,35 +62,55 @@ func (p *Provider) EventsForDate(ctx context.Context, date time.Time) ([]applica
 		return nil, err
 	}
 
-	start := startOfDay(date)
-	end := start.Add(24 * time.Hour)
+	var out []application.CalendarEvent
+	var pageToken string
+	for {
+		call := p.Svc.Events.List(calID).
+			Context(ctx).
+			TimeMin(from.Format(time.RFC3339)).
+			TimeMax(to.Format(time.RFC3339)).
+			SingleEvents(true).
+			OrderBy("startTime")
+		if pageToken != "" {
+			call = call.PageToken(pageToken)
+		}
 
-	call := p.Svc.Events.List(calID).
-		Context(ctx).
-		TimeMin(start.Format(time.RFC3339)).
-		TimeMax(end.Format(time.RFC3339)).
-		SingleEvents(true).
-		OrderBy("startTime")
+		events, err := call.Do()
+		if err != nil {
+			return nil, fmt.Errorf("google calendar: events.list: %w", err)
+		}
 
-	events, err := call.Do()
-	if err != nil {
-		return nil, fmt.Errorf("google calendar: events.list: %w", err)
-	}
+		for _, ev := range events.Items {
+			if !p.Filter.Keep(filterEvent(ev)) {
+				continue
+			}
+			start := parseEventStart(from.Location(), ev)
+			greeted, greetedTo := greetings(ev.Description, privateProperty(ev, GreetedProperty), start)
+			out = append(out, application.CalendarEvent{
+				ID:          ev.Id,
+				Title:       ev.Summary,
+				Description: ev.Description,
+				StartDate:   start,
+				Metadata:    metadataOf(privateProperties(ev)),
+				Greeted:     greeted,
+				GreetedTo:   greetedTo,
+			})
+		}
 
-	out := make([]application.CalendarEvent, 0, len(events.Items))
-	for _, ev := range events.Items {
-		out = append(out, application.CalendarEvent{
-			ID:          ev.Id,
-			Title:       ev.Summary,
-			Description: ev.Description,
-			StartDate:   parseEventStart(date.Location(), ev),
-		})
+		if events.NextPageToken == "" {
+			break
+		}
+		pageToken = events.NextPageToken
 	}
 
 	return out, nil
 }
 
 func (p *Provider) calendarID(ctx context.Context) (string, error) {
+	if id := strings.TrimSpace(p.CalendarID); id != "" {
+		return id, nil
+	}
+
 	p.mu.Lock()
 	if p.cachedCalID != "" {
 		id := p.cachedCalID
@@ -102,6 +152,15 @@ func (p *Provider) calendarID(ctx context.Context) (string, error) {
 	return "", fmt.Errorf("google calendar: calendar %q not found (create it in Google Calendar)", name)
 }
 
+func filterEvent(ev *calendar.Event) filter.Event {
+	return filter.Event{
+		Title:       ev.Summary,
+		Description: ev.Description,
+		Color:       ev.ColorId,
+		AllDay:      ev.Start != nil && ev.Start.Date != "",
+	}
+}
+
 func parseEventStart(loc *time.Location, ev *calendar.Event) time.Time {
 	if ev.Start == nil {
 		return time.Time{}
@@ -0,0 +1,495 @@
+package google
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"google.golang.org/api/calendar/v3"
+
+	"github.com/rafakmp18/gobirth/internal/gobirth/adapters/atomicfile"
+	"github.com/rafakmp18/gobirth/internal/gobirth/adapters/calendar/filter"
+	"github.com/rafakmp18/gobirth/internal/gobirth/adapters/calendar/ics"
+	"github.com/rafakmp18/gobirth/internal/gobirth/application"
+)
+
+// SyncResult describes what changed in the calendar since the previous sync.
+// Added, Updated and Removed hold event titles.
+type SyncResult struct {
+	// Full is set when the whole calendar was downloaded: on the first sync,
+	// after the sync token expired or when the calendar changed.
+	Full    bool
+	Added   []string
+	Updated []string
+	Removed []string
+}
+
+type syncState struct {
+	CalendarID string                 `json:"calendar_id"`
+	SyncToken  string                 `json:"sync_token"`
+	Events     map[string]storedEvent `json:"events"`
+}
+
+// storedEvent keeps the raw Google event rather than its occurrences, so
+// recurring birthdays are expanded locally for any range.
+type storedEvent struct {
+	Summary     string      `json:"summary,omitempty"`
+	Description string      `json:"description,omitempty"`
+	Start       *storedTime `json:"start,omitempty"`
+	// Recurrence holds the RFC 5545 RRULE/EXDATE lines of a master event.
+	Recurrence []string `json:"recurrence,omitempty"`
+	// RecurringEventID and OriginalStart identify a modified or cancelled
+	// instance of a recurring event.
+	RecurringEventID string      `json:"recurring_event_id,omitempty"`
+	OriginalStart    *storedTime `json:"original_start,omitempty"`
+	Cancelled        bool        `json:"cancelled,omitempty"`
+	// Private holds the private extended properties: contact metadata and
+	// the GreetedProperty marker.
+	Private map[string]string `json:"private,omitempty"`
+	ColorID string            `json:"color_id,omitempty"`
+}
+
+type storedTime struct {
+	Date     string `json:"date,omitempty"`
+	DateTime string `json:"date_time,omitempty"`
+	TimeZone string `json:"time_zone,omitempty"`
+}
+
+// Sync brings the local store at StatePath up to date: a full download the
+// first time, or when Google answers 410 Gone to the sync token, and only
+// the changes otherwise.
+func (p *Provider) Sync(ctx context.Context) (SyncResult, error) {
+	if p.Svc == nil {
+		return SyncResult{}, fmt.Errorf("google calendar: nil service")
+	}
+	if p.StatePath == "" {
+		return SyncResult{}, fmt.Errorf("google calendar: sync needs a state path")
+	}
+
+	p.syncMu.Lock()
+	defer p.syncMu.Unlock()
+
+	return p.sync(ctx)
+}
+
+func (p *Provider) storedEventsBetween(ctx context.Context, from, to time.Time) ([]application.CalendarEvent, error) {
+	p.syncMu.Lock()
+	defer p.syncMu.Unlock()
+
+	if p.state == nil || p.MaxAge <= 0 || time.Since(p.syncedAt) > p.MaxAge {
+		if _, err := p.sync(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	cal, rejected := p.state.calendar()
+
+	var out []application.CalendarEvent
+	for _, ev := range cal.EventsBetween(from, to) {
+		ev.ID = p.state.instanceID(ev)
+		stored := p.state.eventOf(ev.ID)
+		if !p.keep(stored, ev.Title, ev.Description) {
+			continue
+		}
+		ev.Metadata = metadataOf(stored.Private)
+		ev.Greeted, ev.GreetedTo = greetings(ev.Description, "", ev.StartDate)
+		out = append(out, ev)
+	}
+
+	// Like bad rows of a CSV file, events that cannot be expanded are only
+	// reported by checks spanning a year, e.g. gobirth validate, rather
+	// than on every daily run.
+	var errs []error
+	if !to.Before(from.AddDate(1, 0, 0)) {
+		ids := make([]string, 0, len(rejected))
+		for id := range rejected {
+			ids = append(ids, id)
+		}
+		sort.Strings(ids)
+		for _, id := range ids {
+			stored := p.state.Events[id]
+			if p.keep(stored, stored.Summary, stored.Description) {
+				errs = append(errs, fmt.Errorf("google calendar: event %q skipped: %w", stored.Summary, rejected[id]))
+			}
+		}
+	}
+	if len(errs) > 0 {
+		return out, &application.PartialFetchError{Errs: errs}
+	}
+	return out, nil
+}
+
+func (p *Provider) keep(stored storedEvent, title, description string) bool {
+	return p.Filter.Keep(filter.Event{
+		Title:       title,
+		Description: description,
+		Color:       stored.ColorID,
+		AllDay:      stored.Start != nil && stored.Start.Date != "",
+	})
+}
+
+func (p *Provider) sync(ctx context.Context) (SyncResult, error) {
+	calID, err := p.calendarID(ctx)
+	if err != nil {
+		return SyncResult{}, err
+	}
+
+	if p.state == nil {
+		st, err := loadSyncState(p.StatePath)
+		if err != nil {
+			return SyncResult{}, err
+		}
+		p.state = st
+	}
+	if p.state.CalendarID != calID {
+		p.state = &syncState{CalendarID: calID, Events: map[string]storedEvent{}}
+	}
+
+	before := make(map[string]storedEvent, len(p.state.Events))
+	for id, ev := range p.state.Events {
+		before[id] = ev
+	}
+
+	full := p.state.SyncToken == ""
+	err = p.list(ctx, calID, p.state.SyncToken)
+	if !full && isExpiredSyncToken(err) {
+		full = true
+		p.state.SyncToken = ""
+		err = p.list(ctx, calID, "")
+	}
+	if err != nil {
+		return SyncResult{}, err
+	}
+
+	if err := saveSyncState(p.StatePath, p.state); err != nil {
+		return SyncResult{}, err
+	}
+	p.syncedAt = time.Now()
+
+	res := diffEvents(before, p.state.Events)
+	res.Full = full
+	return res, nil
+}
+
+func (p *Provider) list(ctx context.Context, calID, syncToken string) error {
+	seen := map[string]storedEvent{}
+
+	var pageToken string
+	for {
+		// Deleted events are needed to drop them from the store and to skip
+		// cancelled instances of recurring birthdays.
+		call := p.Svc.Events.List(calID).
+			Context(ctx).
+			ShowDeleted(true).
+			MaxResults(2500)
+		if syncToken != "" {
+			call = call.SyncToken(syncToken)
+		}
+		if pageToken != "" {
+			call = call.PageToken(pageToken)
+		}
+
+		events, err := call.Do()
+		if err != nil {
+			return fmt.Errorf("google calendar: events.list: %w", err)
+		}
+
+		for _, ev := range events.Items {
+			stored := storeEvent(ev)
+			if stored.Cancelled && stored.RecurringEventID == "" {
+				delete(p.state.Events, ev.Id)
+				continue
+			}
+			seen[ev.Id] = stored
+			p.state.Events[ev.Id] = stored
+		}
+
+		if events.NextPageToken == "" {
+			p.state.SyncToken = events.NextSyncToken
+			break
+		}
+		pageToken = events.NextPageToken
+	}
+
+	if syncToken == "" {
+		p.state.Events = seen
+	}
+	return nil
+}
+
+func storeEvent(ev *calendar.Event) storedEvent {
+	return storedEvent{
+		Summary:          ev.Summary,
+		Description:      ev.Description,
+		Start:            storeTime(ev.Start),
+		Recurrence:       ev.Recurrence,
+		RecurringEventID: ev.RecurringEventId,
+		OriginalStart:    storeTime(ev.OriginalStartTime),
+		Cancelled:        ev.Status == "cancelled",
+		Private:          privateProperties(ev),
+		ColorID:          ev.ColorId,
+	}
+}
+
+func storeTime(t *calendar.EventDateTime) *storedTime {
+	if t == nil || (t.Date == "" && t.DateTime == "") {
+		return nil
+	}
+	return &storedTime{Date: t.Date, DateTime: t.DateTime, TimeZone: t.TimeZone}
+}
+
+// diffEvents compares the visible (not cancelled) events of two stores.
+func diffEvents(before, after map[string]storedEvent) SyncResult {
+	var res SyncResult
+	for id, ev := range after {
+		if ev.Cancelled {
+			continue
+		}
+		old, ok := before[id]
+		switch {
+		case !ok || old.Cancelled:
+			res.Added = append(res.Added, ev.Summary)
+		case !reflect.DeepEqual(old, ev):
+			res.Updated = append(res.Updated, ev.Summary)
+		}
+	}
+	for id, old := range before {
+		if old.Cancelled {
+			continue
+		}
+		if ev, ok := after[id]; !ok || ev.Cancelled {
+			res.Removed = append(res.Removed, old.Summary)
+		}
+	}
+
+	sort.Strings(res.Added)
+	sort.Strings(res.Updated)
+	sort.Strings(res.Removed)
+	return res
+}
+
+// calendar converts the store to iCalendar and parses it, so recurring
+// events expand exactly like those of the ics and caldav providers. Google
+// already keeps recurrence rules as RFC 5545 lines. Events whose rules
+// cannot be expanded are left out, with their modified instances, and
+// returned in rejected by ID.
+func (s *syncState) calendar() (cal *ics.Calendar, rejected map[string]error) {
+	ids := make([]string, 0, len(s.Events))
+	for id := range s.Events {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	rejected = map[string]error{}
+	for _, id := range ids {
+		if err := s.Events[id].checkRecurrence(); err != nil {
+			rejected[id] = err
+		}
+	}
+
+	cal = &ics.Calendar{}
+	for _, id := range ids {
+		ev := s.Events[id]
+		if rejected[id] != nil || rejected[ev.RecurringEventID] != nil {
+			continue
+		}
+		parsed, err := ics.Parse(strings.NewReader(ev.vevent(id)))
+		if err != nil {
+			rejected[id] = err
+			continue
+		}
+		cal.Merge(parsed)
+	}
+	return cal, rejected
+}
+
+// checkRecurrence rejects recurrences that the ics expansion would read as
+// a single event: anything but a plain yearly rule, which is what
+// birthdays and anniversaries use.
+func (e storedEvent) checkRecurrence() error {
+	for _, r := range e.Recurrence {
+		name, value, _ := strings.Cut(r, ":")
+		name, _, _ = strings.Cut(name, ";")
+
+		switch strings.ToUpper(name) {
+		case "EXDATE":
+			continue
+		case "RRULE":
+		default:
+			return fmt.Errorf("unsupported recurrence %q", r)
+		}
+
+		for _, part := range strings.Split(strings.ToUpper(value), ";") {
+			k, v, _ := strings.Cut(part, "=")
+			switch k {
+			case "FREQ":
+				if v != "YEARLY" {
+					return fmt.Errorf("unsupported recurrence %q: only yearly rules are expanded", r)
+				}
+			case "INTERVAL", "COUNT", "UNTIL", "WKST", "BYMONTH", "BYMONTHDAY":
+			default:
+				return fmt.Errorf("unsupported recurrence %q: %s is not expanded", r, k)
+			}
+		}
+	}
+	return nil
+}
+
+// instanceID maps an expanded occurrence to the ID Google gives it, which
+// events.get and events.patch accept: a modified instance keeps the ID of
+// its own event, and the other instances of a recurring event are
+// "<id>_YYYYMMDD" when all-day or "<id>_YYYYMMDDTHHMMSSZ" when timed.
+func (s *syncState) instanceID(ev application.CalendarEvent) string {
+	if master, ok := s.Events[ev.ID]; ok {
+		if len(master.Recurrence) == 0 {
+			return ev.ID
+		}
+		// The ics expansion names modified instances after their master.
+		for id, o := range s.Events {
+			if o.RecurringEventID == ev.ID && !o.Cancelled && o.Start != nil && o.Start.at(ev.StartDate) {
+				return id
+			}
+		}
+		return ev.ID
+	}
+
+	i := strings.LastIndex(ev.ID, "_")
+	if i < 0 {
+		return ev.ID
+	}
+	master, ok := s.Events[ev.ID[:i]]
+	if !ok || master.Start == nil || master.Start.Date != "" {
+		return ev.ID
+	}
+	return ev.ID[:i] + "_" + ev.StartDate.UTC().Format("20060102T150405Z")
+}
+
+// eventOf finds the stored event behind an instance ID: the event itself,
+// or the master of a recurring event, e.g. for "<id>_YYYYMMDD".
+func (s *syncState) eventOf(occurrenceID string) storedEvent {
+	if ev, ok := s.Events[occurrenceID]; ok {
+		return ev
+	}
+	if i := strings.LastIndex(occurrenceID, "_"); i > 0 {
+		return s.Events[occurrenceID[:i]]
+	}
+	return storedEvent{}
+}
+
+func (e storedEvent) vevent(id string) string {
+	var b strings.Builder
+	line := func(s string) { b.WriteString(s + "\r\n") }
+
+	line("BEGIN:VCALENDAR")
+	line("BEGIN:VEVENT")
+	if e.RecurringEventID != "" {
+		line("UID:" + e.RecurringEventID)
+		if e.OriginalStart != nil {
+			line("RECURRENCE-ID" + e.OriginalStart.ics())
+		}
+	} else {
+		line("UID:" + id)
+	}
+
+	// Cancelled instances come without a start; their original one still
+	// excludes them from the master event.
+	start := e.Start
+	if start == nil {
+		start = e.OriginalStart
+	}
+	if start != nil {
+		line("DTSTART" + start.ics())
+	}
+
+	// The property markers travel as description lines, where greetings
+	// finds them once the event is expanded.
+	desc := e.Description
+	if greeted := e.Private[GreetedProperty]; greeted != "" {
+		for _, m := range strings.Split(greeted, "\n") {
+			desc = strings.TrimRight(desc, "\n") + "\n" + greetedPrefix + " " + m
+		}
+	}
+	line("SUMMARY:" + escapeText(e.Summary))
+	line("DESCRIPTION:" + escapeText(desc))
+	for _, r := range e.Recurrence {
+		line(r)
+	}
+	if e.Cancelled {
+		line("STATUS:CANCELLED")
+	}
+	line("END:VEVENT")
+	line("END:VCALENDAR")
+	return b.String()
+}
+
+// at reports whether the time is the start of an occurrence.
+func (t storedTime) at(occ time.Time) bool {
+	if t.Date != "" {
+		return t.Date == occ.Format("2006-01-02")
+	}
+	dt, err := time.Parse(time.RFC3339, t.DateTime)
+	return err == nil && dt.Equal(occ)
+}
+
+// ics formats the time as the parameters and value of a DTSTART-like
+// property, e.g. ";VALUE=DATE:20260116".
+func (t storedTime) ics() string {
+	if t.Date != "" {
+		return ";VALUE=DATE:" + strings.ReplaceAll(t.Date, "-", "")
+	}
+
+	dt, err := time.Parse(time.RFC3339, t.DateTime)
+	if err != nil {
+		return ":" + t.DateTime
+	}
+	if t.TimeZone != "" {
+		if loc, err := time.LoadLocation(t.TimeZone); err == nil {
+			return ";TZID=" + t.TimeZone + ":" + dt.In(loc).Format("20060102T150405")
+		}
+	}
+	return ":" + dt.UTC().Format("20060102T150405Z")
+}
+
+func escapeText(s string) string {
+	return strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\r\n", `\n`, "\n", `\n`).Replace(s)
+}
+
+func loadSyncState(path string) (*syncState, error) {
+	st := &syncState{Events: map[string]storedEvent{}}
+
+	b, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return st, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("google calendar: read sync state: %w", err)
+	}
+
+	if err := json.Unmarshal(b, st); err != nil {
+		// A corrupt state only costs a full sync.
+		return &syncState{Events: map[string]storedEvent{}}, nil
+	}
+	if st.Events == nil {
+		st.Events = map[string]storedEvent{}
+	}
+	return st, nil
+}
+
+// saveSyncState writes the state atomically with 0600 permissions; it holds
+// names and phone numbers.
+func saveSyncState(path string, st *syncState) error {
+	b, err := json.Marshal(st)
+	if err != nil {
+		return fmt.Errorf("google calendar: save sync state: %w", err)
+	}
+	if err := atomicfile.Write(path, b); err != nil {
+		return fmt.Errorf("google calendar: save sync state: %w", err)
+	}
+	return nil
+}
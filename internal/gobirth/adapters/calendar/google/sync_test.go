@@ -0,0 +1,291 @@
+package google
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/api/calendar/v3"
+	"google.golang.org/api/option"
+
+	"github.com/rafakmp18/gobirth/internal/gobirth/application"
+)
+
+// fakeCalendar serves events.list like fakePeople does connections.list: a
+// full listing in two pages without a sync token, the incremental changes
+// with one, and 410 Gone for the token "expired".
+type fakeCalendar struct {
+	t           *testing.T
+	full        []*calendar.Event
+	incremental []*calendar.Event
+	requests    []string
+}
+
+func (f *fakeCalendar) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasSuffix(r.URL.Path, "/calendars/cal/events") {
+		f.t.Errorf("unexpected request %s", r.URL.Path)
+		http.NotFound(w, r)
+		return
+	}
+
+	q := r.URL.Query()
+	f.requests = append(f.requests, q.Get("syncToken"))
+
+	if q.Get("showDeleted") != "true" {
+		f.t.Errorf("showDeleted = %q", q.Get("showDeleted"))
+	}
+
+	var resp calendar.Events
+	switch q.Get("syncToken") {
+	case "":
+		if q.Get("pageToken") == "" {
+			resp = calendar.Events{Items: f.full[:1], NextPageToken: "p2"}
+		} else {
+			resp = calendar.Events{Items: f.full[1:], NextSyncToken: "sync-1"}
+		}
+	case "expired":
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusGone)
+		_, _ = w.Write([]byte(`{"error":{"code":410,"message":"Sync token is no longer valid, a full sync is required."}}`))
+		return
+	default:
+		resp = calendar.Events{Items: f.incremental, NextSyncToken: "sync-2"}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+func newFakeCalendarService(t *testing.T, f *fakeCalendar) *calendar.Service {
+	t.Helper()
+	srv := httptest.NewServer(f)
+	t.Cleanup(srv.Close)
+
+	svc, err := calendar.NewService(context.Background(),
+		option.WithEndpoint(srv.URL+"/"),
+		option.WithHTTPClient(srv.Client()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return svc
+}
+
+func yearly(id, title, desc, date string) *calendar.Event {
+	return &calendar.Event{
+		Id:          id,
+		Summary:     title,
+		Description: desc,
+		Start:       &calendar.EventDateTime{Date: date},
+		Recurrence:  []string{"RRULE:FREQ=YEARLY"},
+	}
+}
+
+func TestProvider_IncrementalSync(t *testing.T) {
+	f := &fakeCalendar{
+		t: t,
+		full: []*calendar.Event{
+			yearly("pepe", "Pepe", "phone: +34600111222\ncontext: del gym, y del k1", "1990-01-16"),
+			{
+				Id:          "ana",
+				Summary:     "Ana",
+				Description: "phone: +34600333444",
+				Start:       &calendar.EventDateTime{DateTime: "2026-01-16T10:00:00+01:00", TimeZone: "Europe/Madrid"},
+			},
+			yearly("luis", "Luis", "phone: +34600555666", "1992-01-16"),
+			{
+				// This year's instance of Luis's birthday was cancelled.
+				Id:                "luis_20260116",
+				Status:            "cancelled",
+				RecurringEventId:  "luis",
+				OriginalStartTime: &calendar.EventDateTime{Date: "2026-01-16"},
+			},
+		},
+		incremental: []*calendar.Event{
+			{Id: "ana", Status: "cancelled"},
+			yearly("pepe", "Pepe", "phone: +34600111999", "1990-01-16"),
+			yearly("marta", "Marta", "phone: +34600777888", "1988-01-16"),
+		},
+	}
+
+	statePath := filepath.Join(t.TempDir(), "sync.json")
+	p := &Provider{Svc: newFakeCalendarService(t, f), CalendarID: "cal", StatePath: statePath}
+	date := time.Date(2026, 1, 16, 9, 0, 0, 0, time.UTC)
+
+	events, err := p.EventsForDate(context.Background(), date)
+	if err != nil {
+		t.Fatalf("first EventsForDate: %v", err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("got %d events, want 2: %+v", len(events), events)
+	}
+	if events[0].ID != "pepe_20260116" || events[0].Description != "phone: +34600111222\ncontext: del gym, y del k1" {
+		t.Errorf("pepe = %+v", events[0])
+	}
+	if events[1].ID != "ana" || !events[1].StartDate.Equal(time.Date(2026, 1, 16, 9, 0, 0, 0, time.UTC)) {
+		t.Errorf("ana = %+v", events[1])
+	}
+
+	// A fresh provider resumes from the stored sync token.
+	p2 := &Provider{Svc: p.Svc, CalendarID: "cal", StatePath: statePath}
+	res, err := p2.Sync(context.Background())
+	if err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	if res.Full || strings.Join(res.Added, ",") != "Marta" ||
+		strings.Join(res.Updated, ",") != "Pepe" || strings.Join(res.Removed, ",") != "Ana" {
+		t.Errorf("sync result = %+v", res)
+	}
+
+	// Within MaxAge queries are answered from the store.
+	p2.MaxAge = time.Hour
+	events, err = p2.EventsForDate(context.Background(), date.AddDate(1, 0, 0))
+	if err != nil {
+		t.Fatalf("third EventsForDate: %v", err)
+	}
+	var titles []string
+	for _, ev := range events {
+		titles = append(titles, ev.Title)
+	}
+	if got := strings.Join(titles, ","); got != "Luis,Marta,Pepe" {
+		t.Errorf("2027 titles = %s, want Luis,Marta,Pepe", got)
+	}
+
+	if got := strings.Join(f.requests, ","); got != ",,sync-1" {
+		t.Errorf("sync tokens sent = %q, want \",,sync-1\"", got)
+	}
+}
+
+func TestProvider_GoneSyncTokenResyncs(t *testing.T) {
+	f := &fakeCalendar{
+		t: t,
+		full: []*calendar.Event{
+			yearly("pepe", "Pepe", "phone: +34600111222", "1990-03-01"),
+			yearly("ana", "Ana", "phone: +34600333444", "1984-02-29"),
+		},
+	}
+
+	statePath := filepath.Join(t.TempDir(), "sync.json")
+	stale := &syncState{
+		CalendarID: "cal",
+		SyncToken:  "expired",
+		Events:     map[string]storedEvent{"old": {Summary: "Gone", Start: &storedTime{Date: "2027-03-01"}}},
+	}
+	if err := saveSyncState(statePath, stale); err != nil {
+		t.Fatal(err)
+	}
+
+	p := &Provider{Svc: newFakeCalendarService(t, f), CalendarID: "cal", StatePath: statePath}
+	res, err := p.Sync(context.Background())
+	if err != nil {
+		t.Fatalf("Sync: %v", err)
+	}
+	if !res.Full || strings.Join(res.Removed, ",") != "Gone" {
+		t.Errorf("sync result = %+v", res)
+	}
+
+	st, err := loadSyncState(statePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if st.SyncToken != "sync-1" || len(st.Events) != 2 {
+		t.Errorf("state after resync = %+v", st)
+	}
+}
+
+func TestProvider_SyncedOverrideIsRecordedOnItsOwnInstance(t *testing.T) {
+	master := yearly("pepe", "Pepe", "phone: +34600111222", "1990-01-16")
+	f := &fakeEventsAPI{t: t, events: map[string]*calendar.Event{
+		"pepe": master,
+		// This year's party was moved to the evening of the 17th.
+		"pepe_20260116": {
+			Id:                "pepe_20260116",
+			Summary:           "Pepe",
+			Description:       "phone: +34600111222\ncontext: fiesta",
+			Start:             &calendar.EventDateTime{Date: "2026-01-17"},
+			RecurringEventId:  "pepe",
+			OriginalStartTime: &calendar.EventDateTime{Date: "2026-01-16"},
+		},
+	}}
+	p := &Provider{
+		Svc:        newFakeEventsService(t, f),
+		CalendarID: "cal",
+		StatePath:  filepath.Join(t.TempDir(), "sync.json"),
+		WriteBack:  WriteBackProperty,
+	}
+	date := time.Date(2026, 1, 17, 0, 0, 0, 0, time.UTC)
+
+	events, err := p.EventsForDate(context.Background(), date)
+	if err != nil || len(events) != 1 || events[0].ID != "pepe_20260116" {
+		t.Fatalf("events = %+v, %v", events, err)
+	}
+
+	if err := p.RecordGreeting(context.Background(), events[0], date, application.ChannelWhatsApp, "+34600111222"); err != nil {
+		t.Fatalf("RecordGreeting: %v", err)
+	}
+	if master.ExtendedProperties != nil {
+		t.Errorf("master was annotated: %+v", master.ExtendedProperties)
+	}
+
+	events, err = p.EventsForDate(context.Background(), date)
+	if err != nil || len(events) != 1 || strings.Join(events[0].GreetedTo, ",") != "+34600111222" {
+		t.Fatalf("after = %+v, %v", events, err)
+	}
+}
+
+func TestProvider_SyncedInstanceIDs(t *testing.T) {
+	f := &fakeEventsAPI{t: t, events: map[string]*calendar.Event{
+		"pepe": yearly("pepe", "Pepe", "phone: +34600111222", "1990-01-16"),
+		"ana": {
+			Id:          "ana",
+			Summary:     "Ana",
+			Description: "phone: +34600333444",
+			Start:       &calendar.EventDateTime{DateTime: "2020-01-16T10:00:00+01:00", TimeZone: "Europe/Madrid"},
+			Recurrence:  []string{"RRULE:FREQ=YEARLY"},
+		},
+	}}
+	p := &Provider{Svc: newFakeEventsService(t, f), CalendarID: "cal", StatePath: filepath.Join(t.TempDir(), "sync.json")}
+
+	events, err := p.EventsForDate(context.Background(), time.Date(2026, 1, 16, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, ev := range events {
+		ids = append(ids, ev.ID)
+	}
+	if got := strings.Join(ids, ","); got != "pepe_20260116,ana_20260116T090000Z" {
+		t.Errorf("IDs = %s, want pepe_20260116,ana_20260116T090000Z", got)
+	}
+}
+
+func TestProvider_SyncReportsRulesItCannotExpand(t *testing.T) {
+	monthly := yearly("club", "Cuota del club", "", "2026-01-16")
+	monthly.Recurrence = []string{"RRULE:FREQ=MONTHLY"}
+	f := &fakeEventsAPI{t: t, events: map[string]*calendar.Event{
+		"pepe": yearly("pepe", "Pepe", "phone: +34600111222", "1990-01-16"),
+		"club": monthly,
+	}}
+	p := &Provider{Svc: newFakeEventsService(t, f), CalendarID: "cal", StatePath: filepath.Join(t.TempDir(), "sync.json")}
+	from := time.Date(2026, 1, 16, 0, 0, 0, 0, time.UTC)
+
+	events, err := p.EventsForDate(context.Background(), from)
+	if err != nil || len(events) != 1 || events[0].Title != "Pepe" {
+		t.Fatalf("daily = %+v, %v", events, err)
+	}
+
+	events, err = p.EventsBetween(context.Background(), from, from.AddDate(1, 0, 0))
+	var partial *application.PartialFetchError
+	if !errors.As(err, &partial) || len(partial.Errs) != 1 || !strings.Contains(partial.Errs[0].Error(), "Cuota del club") {
+		t.Fatalf("yearly err = %v", err)
+	}
+	if len(events) != 1 || events[0].Title != "Pepe" {
+		t.Errorf("yearly events = %+v", events)
+	}
+}
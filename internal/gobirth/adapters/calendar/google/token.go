@@ -0,0 +1,218 @@
+package google
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+
+	"golang.org/x/oauth2"
+
+	"github.com/rafakmp18/gobirth/internal/gobirth/adapters/atomicfile"
+	"github.com/rafakmp18/gobirth/internal/gobirth/application"
+)
+
+// ErrTokenRevoked is returned when Google rejects the stored refresh token,
+// typically because access was revoked or the token expired.
+var ErrTokenRevoked = errors.New(`google auth: stored token was revoked or expired, run "gobirth auth google"`)
+
+// ErrNotAuthorized is returned when no usable token is stored. Only
+// Authorize runs the interactive flow, so that unattended runs fail instead
+// of waiting for a browser.
+var ErrNotAuthorized = errors.New(`google auth: no stored token, run "gobirth auth google"`)
+
+var revokeURL = "https://oauth2.googleapis.com/revoke"
+
+// TokenSecretKey is the default key of the OAuth token when AuthConfig.Secrets
+// is set.
+const TokenSecretKey = "google-token"
+
+// tokenStore is where the OAuth token lives: a plain JSON file (the historical
+// default) or an application.SecretStore.
+type tokenStore interface {
+	Load(ctx context.Context) (*oauth2.Token, error)
+	Save(ctx context.Context, tok *oauth2.Token) error
+	Delete(ctx context.Context) error
+	Location() string
+}
+
+func newTokenStore(cfg AuthConfig) tokenStore {
+	if cfg.Secrets != nil {
+		key := cfg.TokenKey
+		if key == "" {
+			key = TokenSecretKey
+		}
+		return secretTokenStore{store: cfg.Secrets, key: key}
+	}
+	return fileTokenStore{path: cfg.TokenPath}
+}
+
+type fileTokenStore struct {
+	path string
+}
+
+func (s fileTokenStore) Load(ctx context.Context) (*oauth2.Token, error) {
+	return tokenFromFile(s.path)
+}
+
+func (s fileTokenStore) Save(ctx context.Context, tok *oauth2.Token) error {
+	return saveToken(s.path, tok)
+}
+
+func (s fileTokenStore) Delete(ctx context.Context) error {
+	if err := os.Remove(s.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("google auth: delete token: %w", err)
+	}
+	return nil
+}
+
+func (s fileTokenStore) Location() string {
+	return s.path
+}
+
+type secretTokenStore struct {
+	store application.SecretStore
+	key   string
+}
+
+func (s secretTokenStore) Load(ctx context.Context) (*oauth2.Token, error) {
+	b, err := s.store.Get(ctx, s.key)
+	if err != nil {
+		return nil, err
+	}
+
+	var tok oauth2.Token
+	if err := json.Unmarshal(b, &tok); err != nil {
+		return nil, fmt.Errorf("google auth: decode token secret: %w", err)
+	}
+	return &tok, nil
+}
+
+func (s secretTokenStore) Save(ctx context.Context, tok *oauth2.Token) error {
+	b, err := json.Marshal(tok)
+	if err != nil {
+		return fmt.Errorf("google auth: encode token: %w", err)
+	}
+	if err := s.store.Set(ctx, s.key, b); err != nil {
+		return fmt.Errorf("google auth: save token: %w", err)
+	}
+	return nil
+}
+
+func (s secretTokenStore) Delete(ctx context.Context) error {
+	if err := s.store.Delete(ctx, s.key); err != nil {
+		return fmt.Errorf("google auth: delete token: %w", err)
+	}
+	return nil
+}
+
+func (s secretTokenStore) Location() string {
+	return "secret " + s.key
+}
+
+// persistingTokenSource saves every new token returned by base, so refreshed
+// access tokens and rotated refresh tokens survive restarts. Read-only
+// stores, such as env, keep the new token in memory only.
+type persistingTokenSource struct {
+	base  oauth2.TokenSource
+	store tokenStore
+
+	mu   sync.Mutex
+	last *oauth2.Token
+}
+
+func newPersistingTokenSource(base oauth2.TokenSource, store tokenStore, current *oauth2.Token) *persistingTokenSource {
+	return &persistingTokenSource{base: base, store: store, last: current}
+}
+
+func (s *persistingTokenSource) Token() (*oauth2.Token, error) {
+	tok, err := s.base.Token()
+	if err != nil {
+		var rErr *oauth2.RetrieveError
+		if errors.As(err, &rErr) && rErr.ErrorCode == "invalid_grant" {
+			return nil, fmt.Errorf("%w (%v)", ErrTokenRevoked, rErr.ErrorDescription)
+		}
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.last != nil && s.last.AccessToken == tok.AccessToken && s.last.RefreshToken == tok.RefreshToken {
+		return tok, nil
+	}
+
+	if err := s.store.Save(context.Background(), tok); err != nil && !errors.Is(err, application.ErrSecretReadOnly) {
+		return nil, err
+	}
+	s.last = tok
+	return tok, nil
+}
+
+func tokenFromFile(path string) (*oauth2.Token, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var tok oauth2.Token
+	if err := json.NewDecoder(f).Decode(&tok); err != nil {
+		return nil, err
+	}
+	return &tok, nil
+}
+
+// saveToken writes the token atomically, so a crash never leaves a
+// truncated token behind.
+func saveToken(path string, token *oauth2.Token) error {
+	b, err := json.Marshal(token)
+	if err != nil {
+		return fmt.Errorf("google auth: save token: %w", err)
+	}
+	if err := atomicfile.Write(path, b); err != nil {
+		return fmt.Errorf("google auth: save token: %w", err)
+	}
+	return nil
+}
+
+// RevokeToken revokes the stored token at Google and deletes it locally.
+// The local copy is removed even if Google already considers it invalid.
+func RevokeToken(ctx context.Context, cfg AuthConfig) error {
+	store := newTokenStore(cfg)
+
+	tok, err := store.Load(ctx)
+	if err != nil {
+		return fmt.Errorf("google auth: read token: %w", err)
+	}
+
+	value := tok.RefreshToken
+	if value == "" {
+		value = tok.AccessToken
+	}
+
+	form := url.Values{"token": {value}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, revokeURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("google auth: revoke: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("google auth: revoke: %w", err)
+	}
+	resp.Body.Close()
+
+	// 400 means the token was already invalid; it is gone either way.
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusBadRequest {
+		return fmt.Errorf("google auth: revoke: unexpected status %s", resp.Status)
+	}
+
+	return store.Delete(ctx)
+}
@@ -0,0 +1,161 @@
+package google
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"golang.org/x/oauth2"
+
+	"github.com/rafakmp18/gobirth/internal/gobirth/adapters/secrets/env"
+	"github.com/rafakmp18/gobirth/internal/gobirth/application"
+)
+
+type stubTokenSource struct {
+	tok *oauth2.Token
+	err error
+}
+
+func (s stubTokenSource) Token() (*oauth2.Token, error) { return s.tok, s.err }
+
+func TestPersistingTokenSource_SavesRotatedToken(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "token.json")
+	old := &oauth2.Token{AccessToken: "a1", RefreshToken: "r1", Expiry: time.Now().Add(-time.Minute)}
+	if err := saveToken(path, old); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	rotated := &oauth2.Token{AccessToken: "a2", RefreshToken: "r2", Expiry: time.Now().Add(time.Hour)}
+	ts := newPersistingTokenSource(stubTokenSource{tok: rotated}, fileTokenStore{path: path}, old)
+
+	if _, err := ts.Token(); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+
+	got, err := tokenFromFile(path)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if got.AccessToken != "a2" || got.RefreshToken != "r2" {
+		t.Fatalf("expected rotated token on disk, got %+v", got)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("stat: %v", err)
+	}
+	if info.Mode().Perm() != 0o600 {
+		t.Fatalf("expected 0600 permissions, got %v", info.Mode().Perm())
+	}
+}
+
+func TestPersistingTokenSource_ReportsRevokedToken(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "token.json")
+	ts := newPersistingTokenSource(stubTokenSource{err: &oauth2.RetrieveError{ErrorCode: "invalid_grant"}}, fileTokenStore{path: path}, nil)
+
+	_, err := ts.Token()
+	if !errors.Is(err, ErrTokenRevoked) {
+		t.Fatalf("expected ErrTokenRevoked, got %v", err)
+	}
+}
+
+func TestRevokeToken_RevokesAndDeletes(t *testing.T) {
+	var revoked string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		revoked = r.Form.Get("token")
+	}))
+	defer srv.Close()
+
+	prev := revokeURL
+	revokeURL = srv.URL
+	defer func() { revokeURL = prev }()
+
+	path := filepath.Join(t.TempDir(), "token.json")
+	if err := saveToken(path, &oauth2.Token{AccessToken: "a1", RefreshToken: "r1"}); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	if err := RevokeToken(context.Background(), AuthConfig{TokenPath: path}); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if revoked != "r1" {
+		t.Fatalf("expected refresh token to be revoked, got %q", revoked)
+	}
+	if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("expected token file to be deleted, got %v", err)
+	}
+}
+
+type memorySecrets map[string][]byte
+
+func (m memorySecrets) Get(ctx context.Context, key string) ([]byte, error) {
+	v, ok := m[key]
+	if !ok {
+		return nil, application.ErrSecretNotFound
+	}
+	return v, nil
+}
+
+func (m memorySecrets) Set(ctx context.Context, key string, value []byte) error {
+	m[key] = value
+	return nil
+}
+
+func (m memorySecrets) Delete(ctx context.Context, key string) error {
+	delete(m, key)
+	return nil
+}
+
+func TestPersistingTokenSource_SavesToSecretStore(t *testing.T) {
+	secrets := memorySecrets{}
+	store := newTokenStore(AuthConfig{TokenPath: "/nonexistent/token.json", Secrets: secrets})
+
+	rotated := &oauth2.Token{AccessToken: "a2", RefreshToken: "r2"}
+	ts := newPersistingTokenSource(stubTokenSource{tok: rotated}, store, nil)
+
+	if _, err := ts.Token(); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+
+	got, err := store.Load(context.Background())
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if got.RefreshToken != "r2" {
+		t.Fatalf("expected rotated token in secret store, got %+v", got)
+	}
+}
+
+func TestPersistingTokenSource_RefreshesThroughEnvStore(t *testing.T) {
+	secrets := env.Store{LookupEnv: func(name string) (string, bool) {
+		if name != "GOBIRTH_SECRET_GOOGLE_TOKEN" {
+			return "", false
+		}
+		return `{"access_token":"expired","refresh_token":"r1","expiry":"2020-01-01T00:00:00Z"}`, true
+	}}
+	store := newTokenStore(AuthConfig{Secrets: secrets})
+
+	current, err := store.Load(context.Background())
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+
+	refreshed := &oauth2.Token{AccessToken: "fresh", RefreshToken: "r1"}
+	ts := newPersistingTokenSource(stubTokenSource{tok: refreshed}, store, current)
+
+	for i := 0; i < 2; i++ {
+		tok, err := ts.Token()
+		if err != nil {
+			t.Fatalf("call %d: expected nil error from a read-only store, got %v", i+1, err)
+		}
+		if tok.AccessToken != "fresh" {
+			t.Fatalf("call %d: expected the refreshed token, got %+v", i+1, tok)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package google
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"google.golang.org/api/calendar/v3"
+
+	"github.com/rafakmp18/gobirth/internal/gobirth/application"
+)
+
+// WriteBack selects how a sent greeting is recorded on its event.
+type WriteBack string
+
+const (
+	// WriteBackProperty stores the marker in the private extended property
+	// GreetedProperty, invisible in the Google Calendar UI.
+	WriteBackProperty WriteBack = "property"
+	// WriteBackDescription appends a "greeted: 2026-05-03 via whatsapp" line
+	// to the description.
+	WriteBackDescription WriteBack = "description"
+)
+
+// GreetedProperty is the private extended property holding the marker.
+const GreetedProperty = "gobirth.greeted"
+
+const greetedPrefix = "greeted:"
+
+// RecordGreeting annotates the event of a sent greeting with the date,
+// channel and recipient, e.g. "2026-05-03 via whatsapp to +34600111222".
+// Events already carrying the marker for that date and recipient are left
+// untouched, so retries are harmless. Occurrences of recurring events are
+// annotated one by one.
+func (p *Provider) RecordGreeting(ctx context.Context, ev application.CalendarEvent, date time.Time, channel, to string) error {
+	if p.Svc == nil {
+		return fmt.Errorf("google calendar: nil service")
+	}
+	if p.WriteBack != WriteBackProperty && p.WriteBack != WriteBackDescription {
+		return fmt.Errorf("google calendar: write-back is disabled")
+	}
+
+	calID, err := p.calendarID(ctx)
+	if err != nil {
+		return err
+	}
+
+	current, err := p.Svc.Events.Get(calID, ev.ID).Context(ctx).Do()
+	if err != nil {
+		return fmt.Errorf("google calendar: events.get: %w", err)
+	}
+	property := privateProperty(current, GreetedProperty)
+	all, done := greetings(current.Description, property, date)
+	if all || slices.Contains(done, to) {
+		return nil
+	}
+
+	marker := fmt.Sprintf("%s via %s to %s", date.Format("2006-01-02"), channel, to)
+
+	patch := &calendar.Event{}
+	switch p.WriteBack {
+	case WriteBackProperty:
+		private := map[string]string{}
+		if current.ExtendedProperties != nil {
+			for k, v := range current.ExtendedProperties.Private {
+				private[k] = v
+			}
+		}
+		// The property keeps the markers of the latest day only.
+		markers := []string{marker}
+		for _, m := range strings.Split(property, "\n") {
+			if strings.HasPrefix(m, date.Format("2006-01-02")) {
+				markers = append([]string{m}, markers...)
+			}
+		}
+		private[GreetedProperty] = strings.Join(markers, "\n")
+		patch.ExtendedProperties = &calendar.EventExtendedProperties{Private: private}
+	case WriteBackDescription:
+		desc := strings.TrimRight(current.Description, "\n")
+		if desc != "" {
+			desc += "\n"
+		}
+		patch.Description = desc + greetedPrefix + " " + marker
+	}
+
+	if _, err := p.Svc.Events.Patch(calID, ev.ID, patch).Context(ctx).Do(); err != nil {
+		return fmt.Errorf("google calendar: events.patch: %w", err)
+	}
+	return nil
+}
+
+// greetings reads the markers of day, from the property or from the
+// "greeted:" lines of the description: to lists the recipients greeted,
+// and all is set by markers naming no recipient, which older versions
+// wrote once the whole event was greeted.
+func greetings(description, property string, day time.Time) (all bool, to []string) {
+	var markers []string
+	for _, m := range strings.Split(property, "\n") {
+		markers = append(markers, strings.TrimSpace(m))
+	}
+	for _, line := range strings.Split(description, "\n") {
+		l := strings.TrimSpace(line)
+		if len(l) >= len(greetedPrefix) && strings.EqualFold(l[:len(greetedPrefix)], greetedPrefix) {
+			markers = append(markers, strings.TrimSpace(l[len(greetedPrefix):]))
+		}
+	}
+
+	want := day.Format("2006-01-02")
+	for _, m := range markers {
+		if !strings.HasPrefix(m, want) {
+			continue
+		}
+		if _, recipient, ok := strings.Cut(m, " to "); ok {
+			to = append(to, strings.TrimSpace(recipient))
+		} else {
+			all = true
+		}
+	}
+	return all, to
+}
+
+func privateProperty(ev *calendar.Event, key string) string {
+	return privateProperties(ev)[key]
+}
+
+func privateProperties(ev *calendar.Event) map[string]string {
+	if ev.ExtendedProperties == nil {
+		return nil
+	}
+	return ev.ExtendedProperties.Private
+}
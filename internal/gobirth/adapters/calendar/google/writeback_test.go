@@ -0,0 +1,161 @@
+package google
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/api/calendar/v3"
+	"google.golang.org/api/option"
+
+	"github.com/rafakmp18/gobirth/internal/gobirth/application"
+)
+
+// fakeEventsAPI keeps events by ID and serves events.list, events.get and
+// events.patch on the calendar "cal", merging patches like Google does.
+type fakeEventsAPI struct {
+	t       *testing.T
+	events  map[string]*calendar.Event
+	patches int
+	// readOnly refuses every patch, like a token without the events scope.
+	readOnly bool
+}
+
+func (f *fakeEventsAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	const prefix = "/calendars/cal/events"
+	if !strings.HasPrefix(r.URL.Path, prefix) {
+		f.t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		http.NotFound(w, r)
+		return
+	}
+	id := strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, prefix), "/")
+
+	var resp any
+	switch {
+	case r.Method == http.MethodGet && id == "":
+		list := &calendar.Events{}
+		for _, ev := range f.events {
+			list.Items = append(list.Items, ev)
+		}
+		resp = list
+	case r.Method == http.MethodGet:
+		ev, ok := f.events[id]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		resp = ev
+	case r.Method == http.MethodPatch && f.readOnly:
+		http.Error(w, `{"error":{"code":403,"message":"Insufficient Permission"}}`, http.StatusForbidden)
+		return
+	case r.Method == http.MethodPatch:
+		// Decoded twice: raw tells an empty description from an absent one.
+		body, _ := io.ReadAll(r.Body)
+		var (
+			raw   map[string]json.RawMessage
+			patch calendar.Event
+		)
+		if err := json.Unmarshal(body, &raw); err != nil {
+			f.t.Fatal(err)
+		}
+		_ = json.Unmarshal(body, &patch)
+
+		ev := f.events[id]
+		if _, ok := raw["description"]; ok {
+			ev.Description = patch.Description
+		}
+		if patch.ExtendedProperties != nil {
+			ev.ExtendedProperties = patch.ExtendedProperties
+		}
+		f.patches++
+		resp = ev
+	default:
+		f.t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		http.Error(w, "unsupported", http.StatusMethodNotAllowed)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+func newFakeEventsService(t *testing.T, f *fakeEventsAPI) *calendar.Service {
+	t.Helper()
+	srv := httptest.NewServer(f)
+	t.Cleanup(srv.Close)
+
+	svc, err := calendar.NewService(context.Background(),
+		option.WithEndpoint(srv.URL+"/"),
+		option.WithHTTPClient(srv.Client()),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return svc
+}
+
+func TestProvider_RecordGreetingAsProperty(t *testing.T) {
+	f := &fakeEventsAPI{t: t, events: map[string]*calendar.Event{
+		"pepe_20260503": {
+			Id:                 "pepe_20260503",
+			Summary:            "Pepe",
+			Description:        "phone: +34600111222",
+			Start:              &calendar.EventDateTime{Date: "2026-05-03"},
+			ExtendedProperties: &calendar.EventExtendedProperties{Private: map[string]string{"other": "kept"}},
+		},
+	}}
+	p := &Provider{Svc: newFakeEventsService(t, f), CalendarID: "cal", WriteBack: WriteBackProperty}
+	date := time.Date(2026, 5, 3, 0, 0, 0, 0, time.UTC)
+
+	events, err := p.EventsForDate(context.Background(), date)
+	if err != nil || len(events) != 1 || events[0].Greeted || len(events[0].GreetedTo) != 0 {
+		t.Fatalf("before = %+v, %v", events, err)
+	}
+
+	for i, to := range []string{"+34600111222", "+34600111222", "group:team@g.us"} {
+		if err := p.RecordGreeting(context.Background(), events[0], date, application.ChannelWhatsApp, to); err != nil {
+			t.Fatalf("RecordGreeting #%d: %v", i+1, err)
+		}
+	}
+	if f.patches != 2 {
+		t.Errorf("patches = %d, want 2 (the second call sees the marker)", f.patches)
+	}
+
+	private := f.events["pepe_20260503"].ExtendedProperties.Private
+	if private[GreetedProperty] != "2026-05-03 via whatsapp to +34600111222\n2026-05-03 via whatsapp to group:team@g.us" || private["other"] != "kept" {
+		t.Errorf("private properties = %v", private)
+	}
+
+	events, err = p.EventsForDate(context.Background(), date)
+	if err != nil || len(events) != 1 || len(events[0].GreetedTo) != 2 || events[0].GreetedTo[1] != "group:team@g.us" {
+		t.Fatalf("after = %+v, %v", events, err)
+	}
+}
+
+func TestProvider_RecordGreetingInDescription(t *testing.T) {
+	f := &fakeEventsAPI{t: t, events: map[string]*calendar.Event{
+		"ana": {Id: "ana", Summary: "Ana", Description: "phone: +34600333444\n", Start: &calendar.EventDateTime{Date: "2026-05-03"}},
+	}}
+	p := &Provider{Svc: newFakeEventsService(t, f), CalendarID: "cal", WriteBack: WriteBackDescription}
+	date := time.Date(2026, 5, 3, 0, 0, 0, 0, time.UTC)
+
+	ev := application.CalendarEvent{ID: "ana", Title: "Ana", StartDate: date}
+	if err := p.RecordGreeting(context.Background(), ev, date, application.ChannelWhatsApp, "+34600333444"); err != nil {
+		t.Fatalf("RecordGreeting: %v", err)
+	}
+
+	if got := f.events["ana"].Description; got != "phone: +34600333444\ngreeted: 2026-05-03 via whatsapp to +34600333444" {
+		t.Errorf("description = %q", got)
+	}
+	if _, to := greetings(f.events["ana"].Description, "", date.AddDate(1, 0, 0)); len(to) != 0 {
+		t.Error("a marker from 2026 must not count as greeted in 2027")
+	}
+	if all, _ := greetings("greeted: 2026-05-03 via whatsapp", "", date); !all {
+		t.Error("a marker without recipient counts for the whole event")
+	}
+}
@@ -0,0 +1,169 @@
+package ics
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	"github.com/rafakmp18/gobirth/internal/gobirth/application"
+	"github.com/rafakmp18/gobirth/internal/gobirth/domain"
+)
+
+// Calendar is a parsed set of VEVENTs, possibly merged from several
+// iCalendar documents. Other adapters (e.g. CalDAV) use it to expand the
+// raw calendar data they download.
+type Calendar struct {
+	events []vevent
+}
+
+func Parse(r io.Reader) (*Calendar, error) {
+	events, err := parseCalendar(r)
+	if err != nil {
+		return nil, err
+	}
+	return &Calendar{events: events}, nil
+}
+
+func (c *Calendar) Merge(other *Calendar) {
+	c.events = append(c.events, other.events...)
+}
+
+// EventsBetween returns the occurrences starting within [from, to), sorted by
+// start, with times expressed in from's location.
+func (c *Calendar) EventsBetween(from, to time.Time) []application.CalendarEvent {
+	// Instances moved or cancelled through RECURRENCE-ID are excluded from
+	// their master event; the override itself is a single event.
+	overridden := map[string][]time.Time{}
+	for _, ev := range c.events {
+		if ev.RecurrenceID != nil {
+			overridden[ev.UID] = append(overridden[ev.UID], *ev.RecurrenceID)
+		}
+	}
+
+	var out []application.CalendarEvent
+	for _, ev := range c.events {
+		if ev.Cancelled {
+			continue
+		}
+
+		excluded := append(append([]time.Time{}, ev.ExDates...), overridden[ev.UID]...)
+		if ev.RecurrenceID != nil {
+			excluded = nil
+		}
+
+		for _, occ := range occurrences(ev, from, to, excluded) {
+			out = append(out, application.CalendarEvent{
+				ID:          occurrenceID(ev, occ),
+				Title:       ev.Summary,
+				Description: ev.Description,
+				StartDate:   occ,
+			})
+		}
+	}
+
+	sort.SliceStable(out, func(i, j int) bool {
+		return out[i].StartDate.Before(out[j].StartDate)
+	})
+	return out
+}
+
+// occurrences returns the starts of ev within [from, to), in from's location.
+func occurrences(ev vevent, from, to time.Time, excluded []time.Time) []time.Time {
+	loc := from.Location()
+
+	if ev.RRule == nil || ev.RecurrenceID != nil || ev.RRule.Freq != "YEARLY" {
+		occ := inLocation(ev, ev.Start, loc)
+		if inRange(occ, from, to) {
+			return []time.Time{occ}
+		}
+		return nil
+	}
+
+	rr := ev.RRule
+	startYear := ev.Start.Year()
+
+	var out []time.Time
+	for y := startYear; y <= to.Year(); y++ {
+		if (y-startYear)%rr.Interval != 0 {
+			continue
+		}
+		if rr.Count > 0 && (y-startYear)/rr.Interval >= rr.Count {
+			break
+		}
+
+		occ, ok := yearlyOccurrence(ev, y)
+		if !ok {
+			// e.g. a timed Feb 29 event in a common year; RFC 5545 skips
+			// invalid dates.
+			continue
+		}
+		if !rr.Until.IsZero() && occ.After(rr.Until) && !sameDay(occ, rr.Until) {
+			break
+		}
+		if isExcluded(ev, occ, excluded) {
+			continue
+		}
+
+		local := inLocation(ev, occ, loc)
+		if inRange(local, from, to) {
+			out = append(out, local)
+		}
+	}
+	return out
+}
+
+// yearlyOccurrence returns the start of ev in year. All-day events are
+// birthdays and anniversaries, so Feb 29 falls on Feb 28 in common years,
+// like in every other provider.
+func yearlyOccurrence(ev vevent, year int) (time.Time, bool) {
+	s := ev.Start
+	if ev.AllDay {
+		return domain.Anniversary(year, s.Month(), s.Day(), s.Location()), true
+	}
+	t := time.Date(year, s.Month(), s.Day(), s.Hour(), s.Minute(), s.Second(), 0, s.Location())
+	return t, t.Month() == s.Month() && t.Day() == s.Day()
+}
+
+// inLocation converts an occurrence to loc. All-day events keep their
+// calendar date instead of shifting with the timezone.
+func inLocation(ev vevent, t time.Time, loc *time.Location) time.Time {
+	if ev.AllDay {
+		y, m, d := t.Date()
+		return time.Date(y, m, d, 0, 0, 0, 0, loc)
+	}
+	return t.In(loc)
+}
+
+func isExcluded(ev vevent, occ time.Time, excluded []time.Time) bool {
+	for _, ex := range excluded {
+		if !ev.AllDay {
+			ex = ex.In(ev.Start.Location())
+		}
+		if sameDay(ex, occ) {
+			return true
+		}
+	}
+	return false
+}
+
+func occurrenceID(ev vevent, occ time.Time) string {
+	uid := ev.UID
+	if uid == "" {
+		uid = ev.Summary
+	}
+	if ev.RRule == nil || ev.RecurrenceID != nil {
+		return uid
+	}
+	return fmt.Sprintf("%s_%s", uid, occ.Format("20060102"))
+}
+
+func inRange(t, from, to time.Time) bool {
+	return !t.Before(from) && t.Before(to)
+}
+
+func sameDay(a, b time.Time) bool {
+	ay, am, ad := a.Date()
+	by, bm, bd := b.Date()
+	return ay == by && am == bm && ad == bd
+}
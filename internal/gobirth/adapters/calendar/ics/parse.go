@@ -0,0 +1,201 @@
+package ics
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rafakmp18/gobirth/internal/gobirth/adapters/calendar/contentline"
+)
+
+// vevent holds the VEVENT fields gobirth cares about.
+type vevent struct {
+	UID          string
+	Summary      string
+	Description  string
+	Start        time.Time
+	AllDay       bool
+	RRule        *rrule
+	ExDates      []time.Time
+	RecurrenceID *time.Time
+	Cancelled    bool
+}
+
+// rrule is the subset of RFC 5545 recurrence rules used by birthdays and
+// anniversaries.
+type rrule struct {
+	Freq     string
+	Interval int
+	Count    int
+	Until    time.Time
+}
+
+// parseCalendar reads every VEVENT in r. Timezone definitions (VTIMEZONE)
+// are not interpreted; TZID values are resolved with the system tz database.
+func parseCalendar(r io.Reader) ([]vevent, error) {
+	lines, err := contentline.Unfold(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		events []vevent
+		cur    *vevent
+		depth  int
+	)
+
+	for n, line := range lines {
+		prop, err := contentline.Parse(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n+1, err)
+		}
+
+		switch {
+		case prop.Name == "BEGIN" && strings.EqualFold(prop.Value, "VEVENT"):
+			cur = &vevent{}
+			depth = 0
+			continue
+		case cur == nil:
+			continue
+		case prop.Name == "BEGIN":
+			// Nested components such as VALARM.
+			depth++
+			continue
+		case prop.Name == "END" && depth > 0:
+			depth--
+			continue
+		case prop.Name == "END" && strings.EqualFold(prop.Value, "VEVENT"):
+			if !cur.Start.IsZero() {
+				events = append(events, *cur)
+			}
+			cur = nil
+			continue
+		case depth > 0:
+			continue
+		}
+
+		if err := cur.apply(prop); err != nil {
+			return nil, fmt.Errorf("line %d: %s: %w", n+1, prop.Name, err)
+		}
+	}
+
+	return events, nil
+}
+
+func (ev *vevent) apply(p contentline.Property) error {
+	switch p.Name {
+	case "UID":
+		ev.UID = p.Value
+	case "SUMMARY":
+		ev.Summary = contentline.UnescapeText(p.Value)
+	case "DESCRIPTION":
+		ev.Description = contentline.UnescapeText(p.Value)
+	case "STATUS":
+		ev.Cancelled = strings.EqualFold(p.Value, "CANCELLED")
+	case "DTSTART":
+		t, allDay, err := parseDateTime(p.Value, p.Params)
+		if err != nil {
+			return err
+		}
+		ev.Start, ev.AllDay = t, allDay
+	case "RECURRENCE-ID":
+		t, _, err := parseDateTime(p.Value, p.Params)
+		if err != nil {
+			return err
+		}
+		ev.RecurrenceID = &t
+	case "EXDATE":
+		for _, v := range strings.Split(p.Value, ",") {
+			t, _, err := parseDateTime(v, p.Params)
+			if err != nil {
+				return err
+			}
+			ev.ExDates = append(ev.ExDates, t)
+		}
+	case "RRULE":
+		rr, err := parseRRule(p.Value)
+		if err != nil {
+			return err
+		}
+		ev.RRule = rr
+	}
+	return nil
+}
+
+// parseDateTime handles DATE (VALUE=DATE or 8 digits), UTC date-times
+// ending in Z, date-times with TZID and floating date-times (local time).
+func parseDateTime(value string, params contentline.Params) (time.Time, bool, error) {
+	value = strings.TrimSpace(value)
+
+	if params.Has("VALUE", "DATE") || len(value) == 8 {
+		t, err := time.ParseInLocation("20060102", value, time.Local)
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("invalid date %q", value)
+		}
+		return t, true, nil
+	}
+
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.Parse("20060102T150405Z", value)
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("invalid date-time %q", value)
+		}
+		return t, false, nil
+	}
+
+	loc := time.Local
+	if tzid := params.Get("TZID"); tzid != "" {
+		l, err := time.LoadLocation(tzid)
+		if err != nil {
+			return time.Time{}, false, fmt.Errorf("unknown TZID %q", tzid)
+		}
+		loc = l
+	}
+
+	t, err := time.ParseInLocation("20060102T150405", value, loc)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("invalid date-time %q", value)
+	}
+	return t, false, nil
+}
+
+func parseRRule(value string) (*rrule, error) {
+	rr := &rrule{Interval: 1}
+
+	for _, part := range strings.Split(value, ";") {
+		k, v, ok := strings.Cut(part, "=")
+		if !ok {
+			continue
+		}
+
+		switch strings.ToUpper(k) {
+		case "FREQ":
+			rr.Freq = strings.ToUpper(v)
+		case "INTERVAL":
+			n, err := strconv.Atoi(v)
+			if err != nil || n <= 0 {
+				return nil, fmt.Errorf("invalid INTERVAL %q", v)
+			}
+			rr.Interval = n
+		case "COUNT":
+			n, err := strconv.Atoi(v)
+			if err != nil || n <= 0 {
+				return nil, fmt.Errorf("invalid COUNT %q", v)
+			}
+			rr.Count = n
+		case "UNTIL":
+			t, _, err := parseDateTime(v, nil)
+			if err != nil {
+				return nil, err
+			}
+			rr.Until = t
+		}
+	}
+
+	if rr.Freq == "" {
+		return nil, fmt.Errorf("missing FREQ in %q", value)
+	}
+	return rr, nil
+}
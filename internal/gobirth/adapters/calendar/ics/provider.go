@@ -0,0 +1,86 @@
+package ics
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/rafakmp18/gobirth/internal/gobirth/application"
+)
+
+// Provider reads events from a .ics file, or from every .ics file under a
+// directory. Yearly recurrences (RRULE:FREQ=YEARLY with INTERVAL, COUNT and
+// UNTIL) are expanded; other frequencies only match their DTSTART.
+type Provider struct {
+	Path string
+}
+
+func (p Provider) EventsForDate(ctx context.Context, date time.Time) ([]application.CalendarEvent, error) {
+	start := startOfDay(date)
+	return p.EventsBetween(ctx, start, start.AddDate(0, 0, 1))
+}
+
+func (p Provider) EventsBetween(ctx context.Context, from, to time.Time) ([]application.CalendarEvent, error) {
+	_ = ctx
+
+	cal, err := p.load()
+	if err != nil {
+		return nil, err
+	}
+	return cal.EventsBetween(from, to), nil
+}
+
+func (p Provider) load() (*Calendar, error) {
+	info, err := os.Stat(p.Path)
+	if err != nil {
+		return nil, fmt.Errorf("ics calendar: %w", err)
+	}
+
+	if !info.IsDir() {
+		return loadFile(p.Path)
+	}
+
+	all := &Calendar{}
+	err = filepath.WalkDir(p.Path, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.EqualFold(filepath.Ext(path), ".ics") {
+			return nil
+		}
+
+		cal, err := loadFile(path)
+		if err != nil {
+			return err
+		}
+		all.Merge(cal)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return all, nil
+}
+
+func loadFile(path string) (*Calendar, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("ics calendar: open %s: %w", path, err)
+	}
+	defer f.Close()
+
+	cal, err := Parse(f)
+	if err != nil {
+		return nil, fmt.Errorf("ics calendar: parse %s: %w", path, err)
+	}
+	return cal, nil
+}
+
+func startOfDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,160 @@
+package ics
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const sampleICS = `BEGIN:VCALENDAR
+VERSION:2.0
+PRODID:-//test//EN
+BEGIN:VEVENT
+UID:pepe@example
+SUMMARY:Pepe
+DESCRIPTION:phone: +34600111222\ncontext: colega del gym\, y del k1
+DTSTART;VALUE=DATE:19900116
+RRULE:FREQ=YEARLY
+EXDATE;VALUE=DATE:20250116
+BEGIN:VALARM
+ACTION:DISPLAY
+DESCRIPTION:ignored
+END:VALARM
+END:VEVENT
+BEGIN:VEVENT
+UID:ana@example
+SUMMARY:Ana
+DESCRIPTION:phone: +34600333444\ncontext: este texto está partido en va
+ rias líneas
+DTSTART;TZID=America/New_York:20200116T210000
+RRULE:FREQ=YEARLY;COUNT=10
+END:VEVENT
+BEGIN:VEVENT
+UID:once@example
+SUMMARY:Una vez
+DTSTART;VALUE=DATE:20260116
+END:VEVENT
+BEGIN:VEVENT
+UID:bisiesto@example
+SUMMARY:Bisiesto
+DTSTART;VALUE=DATE:20000229
+RRULE:FREQ=YEARLY
+END:VEVENT
+END:VCALENDAR
+`
+
+func writeICS(t *testing.T, dir, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write ics: %v", err)
+	}
+	return path
+}
+
+func titles(t *testing.T, p Provider, date time.Time) []string {
+	t.Helper()
+
+	events, err := p.EventsForDate(context.Background(), date)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+
+	var out []string
+	for _, ev := range events {
+		out = append(out, ev.Title)
+	}
+	return out
+}
+
+func TestProvider_EventsForDate_ExpandsYearlyEvents(t *testing.T) {
+	path := writeICS(t, t.TempDir(), "birthdays.ics", sampleICS)
+	p := Provider{Path: path}
+
+	madrid, err := time.LoadLocation("Europe/Madrid")
+	if err != nil {
+		t.Skipf("tz database unavailable: %v", err)
+	}
+
+	// Ana's 21:00 New York start is already Jan 17 in Madrid.
+	got := titles(t, p, time.Date(2026, 1, 16, 9, 0, 0, 0, madrid))
+	if strings.Join(got, ",") != "Pepe,Una vez" {
+		t.Fatalf("expected Pepe and Una vez on 2026-01-16, got %v", got)
+	}
+
+	got = titles(t, p, time.Date(2026, 1, 17, 9, 0, 0, 0, madrid))
+	if strings.Join(got, ",") != "Ana" {
+		t.Fatalf("expected Ana on 2026-01-17 in Madrid, got %v", got)
+	}
+
+	if got := titles(t, p, time.Date(2025, 1, 16, 9, 0, 0, 0, madrid)); len(got) != 0 {
+		t.Fatalf("expected EXDATE to remove 2025 occurrence, got %v", got)
+	}
+
+	if got := titles(t, p, time.Date(2031, 1, 17, 9, 0, 0, 0, madrid)); len(got) != 0 {
+		t.Fatalf("expected COUNT=10 to stop Ana's recurrence, got %v", got)
+	}
+
+	if got := titles(t, p, time.Date(2028, 2, 29, 9, 0, 0, 0, madrid)); strings.Join(got, ",") != "Bisiesto" {
+		t.Fatalf("expected Feb 29 occurrence in leap year, got %v", got)
+	}
+	if got := titles(t, p, time.Date(2027, 2, 28, 9, 0, 0, 0, madrid)); strings.Join(got, ",") != "Bisiesto" {
+		t.Fatalf("expected the Feb 29 birthday on Feb 28 in a common year, got %v", got)
+	}
+}
+
+func TestProvider_EventsForDate_MapsFields(t *testing.T) {
+	path := writeICS(t, t.TempDir(), "birthdays.ics", sampleICS)
+
+	events, err := Provider{Path: path}.EventsForDate(context.Background(), time.Date(2026, 1, 16, 9, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if len(events) == 0 {
+		t.Fatalf("expected events")
+	}
+
+	pepe := events[0]
+	if pepe.ID != "pepe@example_20260116" {
+		t.Fatalf("unexpected id %q", pepe.ID)
+	}
+	if pepe.Description != "phone: +34600111222\ncontext: colega del gym, y del k1" {
+		t.Fatalf("unexpected description %q", pepe.Description)
+	}
+}
+
+func TestProvider_ReadsDirectoryAndRecurrenceOverrides(t *testing.T) {
+	dir := t.TempDir()
+	writeICS(t, dir, "a.ics", `BEGIN:VCALENDAR
+BEGIN:VEVENT
+UID:luis@example
+SUMMARY:Luis
+DTSTART;VALUE=DATE:19850310
+RRULE:FREQ=YEARLY
+END:VEVENT
+BEGIN:VEVENT
+UID:luis@example
+RECURRENCE-ID;VALUE=DATE:20260310
+SUMMARY:Luis (celebrado el sábado)
+DTSTART;VALUE=DATE:20260314
+END:VEVENT
+END:VCALENDAR
+`)
+	writeICS(t, dir, "notes.txt", "not a calendar")
+
+	p := Provider{Path: dir}
+
+	if got := titles(t, p, time.Date(2026, 3, 10, 9, 0, 0, 0, time.UTC)); len(got) != 0 {
+		t.Fatalf("expected overridden instance to be moved, got %v", got)
+	}
+	if got := titles(t, p, time.Date(2026, 3, 14, 9, 0, 0, 0, time.UTC)); strings.Join(got, ",") != "Luis (celebrado el sábado)" {
+		t.Fatalf("expected override on 2026-03-14, got %v", got)
+	}
+	if got := titles(t, p, time.Date(2027, 3, 10, 9, 0, 0, 0, time.UTC)); strings.Join(got, ",") != "Luis" {
+		t.Fatalf("expected regular occurrence in 2027, got %v", got)
+	}
+}
@@ -0,0 +1,168 @@
+package multi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rafakmp18/gobirth/internal/gobirth/application"
+	"github.com/rafakmp18/gobirth/internal/gobirth/domain"
+)
+
+// Source is one named calendar provider, e.g. "google" or "vcard".
+type Source struct {
+	Name     string
+	Provider application.CalendarProvider
+}
+
+// Provider queries several sources concurrently and merges their events.
+// The same person found twice on a day is kept once, from the earliest
+// source in Sources, so the order sets the precedence. People are the same
+// when their normalized phones match; the name alone only matches across
+// sources, when one of the two events has no phone, since two people may
+// share a name and a birthday.
+//
+// When some sources fail the events of the others are returned together with
+// an *application.PartialFetchError; only when every source fails is the
+// whole fetch an error.
+type Provider struct {
+	Sources []Source
+}
+
+type sourceResult struct {
+	events []application.CalendarEvent
+	err    error
+}
+
+func (p Provider) EventsForDate(ctx context.Context, date time.Time) ([]application.CalendarEvent, error) {
+	return p.fetch(ctx, func(ctx context.Context, src application.CalendarProvider) ([]application.CalendarEvent, error) {
+		return src.EventsForDate(ctx, date)
+	})
+}
+
+func (p Provider) EventsBetween(ctx context.Context, from, to time.Time) ([]application.CalendarEvent, error) {
+	return p.fetch(ctx, func(ctx context.Context, src application.CalendarProvider) ([]application.CalendarEvent, error) {
+		return application.EventsBetween(ctx, src, from, to)
+	})
+}
+
+func (p Provider) fetch(ctx context.Context, get func(context.Context, application.CalendarProvider) ([]application.CalendarEvent, error)) ([]application.CalendarEvent, error) {
+	if len(p.Sources) == 0 {
+		return nil, errors.New("multi calendar: no sources configured")
+	}
+
+	results := make([]sourceResult, len(p.Sources))
+
+	var wg sync.WaitGroup
+	for i, src := range p.Sources {
+		wg.Add(1)
+		go func(i int, src Source) {
+			defer wg.Done()
+			events, err := get(ctx, src.Provider)
+			results[i] = sourceResult{events: events, err: err}
+		}(i, src)
+	}
+	wg.Wait()
+
+	var (
+		out    []application.CalendarEvent
+		errs   []error
+		failed int
+		seen   = people{phones: map[string]bool{}, names: map[string][]nameSeen{}}
+	)
+	for i, r := range results {
+		name := p.Sources[i].Name
+
+		if r.err != nil {
+			var partial *application.PartialFetchError
+			if !errors.As(r.err, &partial) {
+				errs = append(errs, fmt.Errorf("source %s: %w", name, r.err))
+				failed++
+				continue
+			}
+			for _, err := range partial.Errs {
+				errs = append(errs, fmt.Errorf("source %s: %w", name, err))
+			}
+		}
+
+		for _, ev := range r.events {
+			if seen.add(i, ev) {
+				out = append(out, ev)
+			}
+		}
+	}
+
+	switch {
+	case failed == len(p.Sources):
+		return nil, fmt.Errorf("multi calendar: every source failed: %w", errors.Join(errs...))
+	case len(errs) > 0:
+		return out, &application.PartialFetchError{Errs: errs}
+	}
+	return out, nil
+}
+
+// people remembers who was kept, per day: phones by "phone@day" and names
+// by "name@day".
+type people struct {
+	phones map[string]bool
+	names  map[string][]nameSeen
+}
+
+type nameSeen struct {
+	source   int
+	hasPhone bool
+}
+
+// add reports whether ev, from the source at index source, is a new person
+// and remembers it if so.
+func (s people) add(source int, ev application.CalendarEvent) bool {
+	day := ev.StartDate.Format("2006-01-02")
+	phone := normalizePhone(phoneOf(ev))
+	name := normalizeName(ev.Title)
+
+	if phone != "" && s.phones[phone+"@"+day] {
+		return false
+	}
+	if name != "" {
+		for _, n := range s.names[name+"@"+day] {
+			if n.source != source && (phone == "" || !n.hasPhone) {
+				return false
+			}
+		}
+	}
+
+	if phone != "" {
+		s.phones[phone+"@"+day] = true
+	}
+	if name != "" {
+		s.names[name+"@"+day] = append(s.names[name+"@"+day], nameSeen{source: source, hasPhone: phone != ""})
+	}
+	return true
+}
+
+// phoneOf returns the phone EventParser would use: the structured one, or
+// else the "phone:" line of the description.
+func phoneOf(ev application.CalendarEvent) string {
+	if phone := strings.TrimSpace(ev.Metadata[application.MetaPhone]); phone != "" {
+		return phone
+	}
+	fields, _ := application.ParseDescription(ev.Description)
+	return fields[application.MetaPhone]
+}
+
+// normalizePhone makes "+34 600-111 222" and "+34600111222" match; numbers
+// too short to tell people apart are left out.
+func normalizePhone(phone string) string {
+	phone = domain.NormalizePhone(phone)
+	if len(phone) < 6 {
+		return ""
+	}
+	return phone
+}
+
+func normalizeName(name string) string {
+	return strings.Join(strings.Fields(strings.ToLower(name)), " ")
+}
@@ -0,0 +1,116 @@
+package multi
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rafakmp18/gobirth/internal/gobirth/application"
+)
+
+type fakeSource struct {
+	events []application.CalendarEvent
+	err    error
+}
+
+func (f fakeSource) EventsForDate(ctx context.Context, date time.Time) ([]application.CalendarEvent, error) {
+	return f.events, f.err
+}
+
+var day = time.Date(2026, 1, 16, 0, 0, 0, 0, time.UTC)
+
+func ev(id, title, desc string) application.CalendarEvent {
+	return application.CalendarEvent{ID: id, Title: title, Description: desc, StartDate: day}
+}
+
+func TestProvider_MergesAndDeduplicatesByPrecedence(t *testing.T) {
+	p := Provider{Sources: []Source{
+		{Name: "google", Provider: fakeSource{events: []application.CalendarEvent{
+			ev("g1", "Pepe", "phone: +34600111222\ncontext: from google"),
+		}}},
+		{Name: "vcard", Provider: fakeSource{events: []application.CalendarEvent{
+			ev("v1", "Pepe García", "phone: +34 600 111 222"), // same phone
+			ev("v2", "ana  lópez", "phone: +34600333444"),
+		}}},
+		{Name: "file", Provider: fakeSource{events: []application.CalendarEvent{
+			ev("f1", "Ana López", "context: sin teléfono"), // same name and day, no phone
+			ev("f2", "Luis", ""),
+		}}},
+	}}
+
+	events, err := p.EventsForDate(context.Background(), day)
+	if err != nil {
+		t.Fatalf("EventsForDate: %v", err)
+	}
+
+	var ids []string
+	for _, e := range events {
+		ids = append(ids, e.ID)
+	}
+	if got := strings.Join(ids, ","); got != "g1,v2,f2" {
+		t.Errorf("ids = %s, want g1,v2,f2", got)
+	}
+}
+
+func TestProvider_KeepsNamesakesWithDifferentPhones(t *testing.T) {
+	p := Provider{Sources: []Source{
+		{Name: "google", Provider: fakeSource{events: []application.CalendarEvent{
+			ev("g1", "Pepe", "phone: +34600111222"),
+			ev("g2", "Pepe", "phone: +34600999888"),
+		}}},
+		{Name: "vcard", Provider: fakeSource{events: []application.CalendarEvent{
+			ev("v1", "Pepe", "phone: +34600555666"),
+			ev("v2", "Pepe", ""),
+		}}},
+	}}
+
+	events, err := p.EventsForDate(context.Background(), day)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+
+	var ids []string
+	for _, e := range events {
+		ids = append(ids, e.ID)
+	}
+	if got := strings.Join(ids, ","); got != "g1,g2,v1" {
+		t.Fatalf("expected g1,g2,v1, got %s", got)
+	}
+}
+
+func TestProvider_ToleratesFailingSource(t *testing.T) {
+	boom := errors.New("boom")
+	p := Provider{Sources: []Source{
+		{Name: "google", Provider: fakeSource{err: boom}},
+		{Name: "file", Provider: fakeSource{events: []application.CalendarEvent{ev("f1", "Pepe", "phone: +34600111222")}}},
+	}}
+
+	events, err := p.EventsBetween(context.Background(), day, day.AddDate(0, 0, 1))
+
+	var partial *application.PartialFetchError
+	if !errors.As(err, &partial) {
+		t.Fatalf("err = %v, want PartialFetchError", err)
+	}
+	if len(partial.Errs) != 1 || !errors.Is(err, boom) || !strings.Contains(err.Error(), "source google") {
+		t.Errorf("partial = %v", err)
+	}
+	if len(events) != 1 || events[0].ID != "f1" {
+		t.Errorf("events = %+v", events)
+	}
+}
+
+func TestProvider_AllSourcesFail(t *testing.T) {
+	p := Provider{Sources: []Source{
+		{Name: "google", Provider: fakeSource{err: errors.New("offline")}},
+		{Name: "file", Provider: fakeSource{err: errors.New("missing")}},
+	}}
+
+	events, err := p.EventsForDate(context.Background(), day)
+
+	var partial *application.PartialFetchError
+	if err == nil || errors.As(err, &partial) || events != nil {
+		t.Fatalf("events = %v, err = %v; want a plain error", events, err)
+	}
+}
@@ -0,0 +1,247 @@
+package namedays
+
+import (
+	"bufio"
+	"context"
+	"embed"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rafakmp18/gobirth/internal/gobirth/application"
+	"github.com/rafakmp18/gobirth/internal/gobirth/domain"
+)
+
+//go:embed data/*.txt
+var data embed.FS
+
+// IDPrefix starts the ID of every event the provider makes up.
+const IDPrefix = "nameday:"
+
+// lookahead is how far ahead contacts are searched: every yearly event
+// shows up once in it.
+const lookahead = 366 * 24 * time.Hour
+
+// Provider adds name-day events to the events of Upstream. The contacts are
+// the people with a birthday event in Upstream during the next year; each
+// one whose first name is celebrated on a day in one of Countries gets an
+// event with occasion name-day, the phone and context of the birthday, and
+// an ID starting with IDPrefix. The built-in datasets hold the most common
+// names of each country on fixed dates, not the full calendars.
+type Provider struct {
+	Upstream  application.CalendarProvider
+	Countries []string
+
+	calendars []calendar
+}
+
+// New checks that there is a dataset for every country, e.g. "es", "gr" or
+// "pl".
+func New(upstream application.CalendarProvider, countries []string) (*Provider, error) {
+	p := &Provider{Upstream: upstream, Countries: countries}
+	for _, c := range countries {
+		cal, err := load(c)
+		if err != nil {
+			return nil, err
+		}
+		p.calendars = append(p.calendars, cal)
+	}
+	return p, nil
+}
+
+// Countries lists the countries with a built-in dataset.
+func Countries() []string {
+	entries, _ := data.ReadDir("data")
+	var out []string
+	for _, e := range entries {
+		out = append(out, strings.TrimSuffix(e.Name(), ".txt"))
+	}
+	sort.Strings(out)
+	return out
+}
+
+func (p *Provider) EventsForDate(ctx context.Context, date time.Time) ([]application.CalendarEvent, error) {
+	start := startOfDay(date)
+	return p.EventsBetween(ctx, start, start.AddDate(0, 0, 1))
+}
+
+// EventsBetween returns the Upstream events in [from, to) and the name days
+// in that range. Upstream is queried for at least a year to find the
+// contacts.
+func (p *Provider) EventsBetween(ctx context.Context, from, to time.Time) ([]application.CalendarEvent, error) {
+	fetchTo := to
+	if w := from.Add(lookahead); w.After(fetchTo) {
+		fetchTo = w
+	}
+
+	all, err := application.EventsBetween(ctx, p.Upstream, from, fetchTo)
+	if all == nil && err != nil {
+		return nil, err
+	}
+
+	out := application.EventsIn(all, from, to)
+	people := contacts(all)
+	for day := startOfDay(from); day.Before(to); day = day.AddDate(0, 0, 1) {
+		out = append(out, p.nameDays(day, people, application.EventsIn(out, day, day.AddDate(0, 0, 1)))...)
+	}
+	// A partial fetch still goes up, next to whatever could be read.
+	return out, err
+}
+
+// nameDays makes up the events of day, skipping contacts that already have
+// a name-day event on it.
+func (p *Provider) nameDays(day time.Time, people []contact, existing []application.CalendarEvent) []application.CalendarEvent {
+	done := map[string]bool{}
+	for _, ev := range existing {
+		if fields := application.EventFields(ev); domain.ParseOccasion(fields[application.MetaOccasion]) == domain.OccasionNameDay {
+			done[fold(ev.Title)] = true
+		}
+	}
+
+	var out []application.CalendarEvent
+	for _, cal := range p.calendars {
+		names := cal.days[day.Format("01-02")]
+		if len(names) == 0 {
+			continue
+		}
+		for _, c := range people {
+			if !names[c.firstName] || done[fold(c.ev.Title)] {
+				continue
+			}
+			done[fold(c.ev.Title)] = true
+
+			meta := map[string]string{}
+			for k, v := range c.ev.Metadata {
+				meta[k] = v
+			}
+			meta[application.MetaOccasion] = string(domain.OccasionNameDay)
+
+			out = append(out, application.CalendarEvent{
+				ID:          fmt.Sprintf("%s%s:%s:%s", IDPrefix, cal.country, day.Format("2006-01-02"), c.ev.ID),
+				Title:       c.ev.Title,
+				Description: c.ev.Description,
+				StartDate:   day,
+				Metadata:    meta,
+			})
+		}
+	}
+	return out
+}
+
+// Recorder passes sent greetings on to Next, except those of name-day
+// events, which only exist in gobirth.
+type Recorder struct {
+	Next application.GreetingRecorder
+}
+
+func (r Recorder) RecordGreeting(ctx context.Context, ev application.CalendarEvent, date time.Time, channel, to string) error {
+	if strings.HasPrefix(ev.ID, IDPrefix) || r.Next == nil {
+		return nil
+	}
+	return r.Next.RecordGreeting(ctx, ev, date, channel, to)
+}
+
+type contact struct {
+	firstName string
+	ev        application.CalendarEvent
+}
+
+// contacts picks the birthday events with a phone, once per person.
+func contacts(events []application.CalendarEvent) []contact {
+	var out []contact
+	seen := map[string]bool{}
+	for _, ev := range events {
+		fields := application.EventFields(ev)
+		if fields[application.MetaPhone] == "" || domain.ParseOccasion(fields[application.MetaOccasion]) != domain.OccasionBirthday {
+			continue
+		}
+		name := strings.Fields(fold(ev.Title))
+		if len(name) == 0 || seen[strings.Join(name, " ")] {
+			continue
+		}
+		seen[strings.Join(name, " ")] = true
+		out = append(out, contact{firstName: name[0], ev: ev})
+	}
+	return out
+}
+
+// calendar is the dataset of one country: folded names by "MM-DD".
+type calendar struct {
+	country string
+	days    map[string]map[string]bool
+}
+
+var (
+	loadedMu sync.Mutex
+	loaded   = map[string]calendar{}
+)
+
+func load(country string) (calendar, error) {
+	country = strings.ToLower(strings.TrimSpace(country))
+
+	loadedMu.Lock()
+	defer loadedMu.Unlock()
+	if cal, ok := loaded[country]; ok {
+		return cal, nil
+	}
+
+	f, err := data.Open("data/" + country + ".txt")
+	if err != nil {
+		return calendar{}, fmt.Errorf("name days: no dataset for country %q (have %s)", country, strings.Join(Countries(), ", "))
+	}
+	defer f.Close()
+
+	cal := calendar{country: country, days: map[string]map[string]bool{}}
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		day, names, ok := strings.Cut(line, ":")
+		if !ok {
+			return calendar{}, fmt.Errorf("name days %s: bad line %q", country, line)
+		}
+		set := cal.days[day]
+		if set == nil {
+			set = map[string]bool{}
+			cal.days[day] = set
+		}
+		for _, n := range strings.Split(names, ",") {
+			if n = fold(n); n != "" {
+				set[n] = true
+			}
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return calendar{}, err
+	}
+
+	loaded[country] = cal
+	return cal, nil
+}
+
+var accents = strings.NewReplacer(
+	"á", "a", "à", "a", "â", "a", "ä", "a", "ą", "a",
+	"é", "e", "è", "e", "ê", "e", "ë", "e", "ę", "e",
+	"í", "i", "ì", "i", "î", "i", "ï", "i",
+	"ó", "o", "ò", "o", "ô", "o", "ö", "o",
+	"ú", "u", "ù", "u", "û", "u", "ü", "u",
+	"ñ", "n", "ń", "n", "ç", "c", "ć", "c", "ł", "l",
+	"ś", "s", "ź", "z", "ż", "z",
+	"ά", "α", "έ", "ε", "ή", "η", "ί", "ι", "ϊ", "ι", "ΐ", "ι",
+	"ό", "ο", "ύ", "υ", "ϋ", "υ", "ΰ", "υ", "ώ", "ω", "ς", "σ",
+)
+
+// fold makes names comparable regardless of case and accents, so "José"
+// matches "jose" and "Γιώργος" matches "ΓΙΩΡΓΟΣ".
+func fold(s string) string {
+	return accents.Replace(strings.ToLower(strings.TrimSpace(s)))
+}
+
+func startOfDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,137 @@
+package namedays
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/rafakmp18/gobirth/internal/gobirth/application"
+)
+
+type fakeCalendar struct {
+	events []application.CalendarEvent
+	err    error
+}
+
+func (f fakeCalendar) EventsForDate(ctx context.Context, date time.Time) ([]application.CalendarEvent, error) {
+	return application.EventsIn(f.events, date, date.AddDate(0, 0, 1)), f.err
+}
+
+func (f fakeCalendar) EventsBetween(ctx context.Context, from, to time.Time) ([]application.CalendarEvent, error) {
+	return application.EventsIn(f.events, from, to), f.err
+}
+
+func date(month time.Month, day int) time.Time {
+	return time.Date(2026, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestProvider_EmitsNameDays(t *testing.T) {
+	upstream := fakeCalendar{events: []application.CalendarEvent{
+		{ID: "b1", Title: "Jose García", Description: "phone: +34600111222\ncontext: fan del Betis", StartDate: date(7, 2)},
+		{ID: "b2", Title: "Josefa", Description: "phone: +34600333444", StartDate: date(11, 20)},
+		{ID: "b3", Title: "José Luis", Description: "no phone", StartDate: date(5, 1)},
+		{ID: "a1", Title: "José y Ana", Description: "phone: +34600555666\noccasion: anniversary", StartDate: date(6, 1)},
+		{ID: "today", Title: "Luis", Description: "phone: +34600777888", StartDate: date(3, 19)},
+	}}
+	p, err := New(upstream, []string{"es"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	events, err := p.EventsForDate(context.Background(), date(3, 19))
+	if err != nil {
+		t.Fatalf("EventsForDate: %v", err)
+	}
+
+	got := map[string]application.CalendarEvent{}
+	for _, ev := range events {
+		got[ev.ID] = ev
+	}
+	if len(got) != 3 {
+		t.Fatalf("events = %+v, want today's birthday and two name days", events)
+	}
+	if _, ok := got["today"]; !ok {
+		t.Error("upstream event of the day is missing")
+	}
+
+	jose, ok := got["nameday:es:2026-03-19:b1"]
+	if !ok {
+		t.Fatalf("no name day for Jose: %+v", events)
+	}
+	contact, err := application.EventParser{}.Parse(jose)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if contact.Occasion() != "name-day" || contact.Phone().String() != "+34600111222" || contact.Context() != "fan del Betis" {
+		t.Errorf("contact = %+v", contact)
+	}
+	if _, ok := got["nameday:es:2026-03-19:b2"]; !ok {
+		t.Error("Josefa has her name day on March 19 too")
+	}
+}
+
+func TestProvider_GreekNamesMatchAnySpelling(t *testing.T) {
+	upstream := fakeCalendar{events: []application.CalendarEvent{
+		{ID: "g1", Title: "ΚΩΣΤΑΣ Παπαδόπουλος", Description: "phone: +306900000001", StartDate: date(9, 1)},
+		{ID: "g2", Title: "Kostas", Description: "phone: +306900000002", StartDate: date(10, 1)},
+	}}
+	p, err := New(upstream, []string{"gr", "es"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	events, err := p.EventsBetween(context.Background(), date(5, 20), date(5, 23))
+	if err != nil {
+		t.Fatalf("EventsBetween: %v", err)
+	}
+	if len(events) != 2 || !events[0].StartDate.Equal(date(5, 21)) {
+		t.Errorf("events = %+v, want both Kostas on May 21", events)
+	}
+}
+
+func TestProvider_KeepsExistingNameDayAndPartialErrors(t *testing.T) {
+	partial := &application.PartialFetchError{Errs: []error{errors.New("vcard: boom")}}
+	upstream := fakeCalendar{err: partial, events: []application.CalendarEvent{
+		{ID: "b1", Title: "Pepe", Description: "phone: +34600111222", StartDate: date(8, 1)},
+		{ID: "n1", Title: "Pepe", Description: "phone: +34600111222\noccasion: santo", StartDate: date(3, 19)},
+	}}
+	p, err := New(upstream, []string{"es"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	events, err := p.EventsForDate(context.Background(), date(3, 19))
+	if !errors.As(err, &partial) {
+		t.Errorf("err = %v, want the partial fetch error", err)
+	}
+	if len(events) != 1 || events[0].ID != "n1" {
+		t.Errorf("events = %+v, want only the name day already in the calendar", events)
+	}
+}
+
+func TestNew_UnknownCountry(t *testing.T) {
+	if _, err := New(fakeCalendar{}, []string{"xx"}); err == nil {
+		t.Fatal("expected an error for a country without dataset")
+	}
+}
+
+type recorded struct{ ids []string }
+
+func (r *recorded) RecordGreeting(ctx context.Context, ev application.CalendarEvent, date time.Time, channel, to string) error {
+	r.ids = append(r.ids, ev.ID)
+	return nil
+}
+
+func TestRecorder_SkipsNameDays(t *testing.T) {
+	next := &recorded{}
+	r := Recorder{Next: next}
+	for _, id := range []string{"nameday:es:2026-03-19:b1", "b1"} {
+		if err := r.RecordGreeting(context.Background(), application.CalendarEvent{ID: id}, date(3, 19), application.ChannelWhatsApp, "+34600111222"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(next.ids) != 1 || next.ids[0] != "b1" {
+		t.Errorf("recorded %v, want only b1", next.ids)
+	}
+}
@@ -0,0 +1,191 @@
+package vcard
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rafakmp18/gobirth/internal/gobirth/adapters/calendar/contentline"
+	"github.com/rafakmp18/gobirth/internal/gobirth/domain"
+)
+
+// card holds the vCard properties gobirth cares about.
+type card struct {
+	UID      string
+	FN       string
+	Nickname string
+	N        string
+	Note     string
+	Birthday *birthday
+	Phones   []tel
+	// Errs holds the lines of the card that could not be read, e.g. a
+	// malformed BDAY; such a card is reported instead of greeted.
+	Errs []error
+}
+
+// birthday is a BDAY value. Year is 0 for the year-less --MM-DD form.
+type birthday struct {
+	Year  int
+	Month time.Month
+	Day   int
+}
+
+type tel struct {
+	Number string
+	Types  []string
+	// Pref is the vCard 4.0 PREF value (1 is most preferred) or 1 for a
+	// vCard 3.0 TYPE=pref; 0 means not preferred.
+	Pref int
+}
+
+// parseCards reads every BEGIN:VCARD ... END:VCARD block in r. Malformed
+// lines only spoil their own card, in its Errs; err is set when r itself
+// cannot be read.
+func parseCards(r io.Reader) ([]card, error) {
+	lines, err := contentline.Unfold(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		cards []card
+		cur   *card
+	)
+
+	for n, line := range lines {
+		p, err := contentline.Parse(line)
+		if err != nil {
+			if cur != nil {
+				cur.Errs = append(cur.Errs, fmt.Errorf("line %d: %w", n+1, err))
+			}
+			continue
+		}
+
+		switch {
+		case p.Name == "BEGIN" && strings.EqualFold(p.Value, "VCARD"):
+			cur = &card{}
+			continue
+		case cur == nil:
+			continue
+		case p.Name == "END" && strings.EqualFold(p.Value, "VCARD"):
+			cards = append(cards, *cur)
+			cur = nil
+			continue
+		}
+
+		if err := cur.apply(p); err != nil {
+			cur.Errs = append(cur.Errs, fmt.Errorf("line %d: %s: %w", n+1, p.Name, err))
+		}
+	}
+
+	return cards, nil
+}
+
+func (c *card) apply(p contentline.Property) error {
+	switch p.Name {
+	case "UID":
+		c.UID = p.Value
+	case "FN":
+		c.FN = contentline.UnescapeText(p.Value)
+	case "NICKNAME":
+		// NICKNAME may list several comma-separated names; the first wins.
+		c.Nickname = strings.TrimSpace(splitText(p.Value)[0])
+	case "N":
+		c.N = nameFromN(p.Value)
+	case "NOTE":
+		c.Note = contentline.UnescapeText(p.Value)
+	case "BDAY":
+		if p.Params.Has("VALUE", "text") {
+			return nil
+		}
+		b, err := parseBirthday(p.Value)
+		if err != nil {
+			return err
+		}
+		c.Birthday = &b
+	case "TEL":
+		t := tel{Number: domain.NormalizePhone(strings.TrimPrefix(strings.TrimSpace(p.Value), "tel:"))}
+		for _, v := range p.Params["TYPE"] {
+			typ := strings.ToLower(v)
+			if typ == "pref" {
+				t.Pref = 1
+				continue
+			}
+			t.Types = append(t.Types, typ)
+		}
+		if v := p.Params.Get("PREF"); v != "" {
+			if n, err := strconv.Atoi(v); err == nil && n > 0 {
+				t.Pref = n
+			}
+		}
+		if t.Number != "" {
+			c.Phones = append(c.Phones, t)
+		}
+	}
+	return nil
+}
+
+// parseBirthday accepts the date forms used by vCard 3.0 and 4.0:
+// 19900116, 1990-01-16, --0116, --01-16, optionally followed by a time.
+func parseBirthday(value string) (birthday, error) {
+	v := strings.TrimSpace(value)
+	if i := strings.IndexByte(v, 'T'); i >= 0 {
+		v = v[:i]
+	}
+
+	yearless := strings.HasPrefix(v, "--")
+	v = strings.ReplaceAll(strings.TrimPrefix(v, "--"), "-", "")
+
+	layout := "20060102"
+	if yearless {
+		layout = "0102"
+	}
+
+	t, err := time.Parse(layout, v)
+	if err != nil {
+		// time.Parse rejects --02-29 without a year, so handle it here.
+		if yearless && v == "0229" {
+			return birthday{Month: time.February, Day: 29}, nil
+		}
+		return birthday{}, fmt.Errorf("invalid BDAY %q", value)
+	}
+
+	b := birthday{Month: t.Month(), Day: t.Day()}
+	if !yearless {
+		b.Year = t.Year()
+	}
+	return b, nil
+}
+
+// nameFromN turns "Family;Given;Additional;Prefix;Suffix" into "Given Family".
+func nameFromN(value string) string {
+	parts := strings.Split(value, ";")
+	for len(parts) < 2 {
+		parts = append(parts, "")
+	}
+	return strings.TrimSpace(contentline.UnescapeText(parts[1]) + " " + contentline.UnescapeText(parts[0]))
+}
+
+// splitText splits a comma-separated text list, honouring "\," escapes.
+func splitText(value string) []string {
+	var (
+		out []string
+		cur strings.Builder
+	)
+	for i := 0; i < len(value); i++ {
+		switch {
+		case value[i] == '\\' && i+1 < len(value):
+			cur.WriteByte('\\')
+			cur.WriteByte(value[i+1])
+			i++
+		case value[i] == ',':
+			out = append(out, contentline.UnescapeText(cur.String()))
+			cur.Reset()
+		default:
+			cur.WriteByte(value[i])
+		}
+	}
+	return append(out, contentline.UnescapeText(cur.String()))
+}
@@ -0,0 +1,209 @@
+package vcard
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rafakmp18/gobirth/internal/gobirth/application"
+	"github.com/rafakmp18/gobirth/internal/gobirth/domain"
+)
+
+// Provider reads birthdays from a .vcf file, or from every .vcf file under
+// a directory, and turns each BDAY into a yearly event. The event title is
+// FN (falling back to NICKNAME, then N), the description carries the
+// preferred TEL as "phone:" and NOTE as "context:" so EventParser reads it
+// like any other calendar. Feb 29 birthdays fall on Feb 28 in common years.
+//
+// Cards with malformed lines are skipped and reported, with their file and
+// line, in an *application.PartialFetchError next to the valid events, like
+// bad rows of a CSV file: those whose birthday falls in the range, and all
+// of them when the range spans a year, e.g. for gobirth validate.
+type Provider struct {
+	Path string
+}
+
+func (p Provider) EventsForDate(ctx context.Context, date time.Time) ([]application.CalendarEvent, error) {
+	start := startOfDay(date)
+	return p.EventsBetween(ctx, start, start.AddDate(0, 0, 1))
+}
+
+func (p Provider) EventsBetween(ctx context.Context, from, to time.Time) ([]application.CalendarEvent, error) {
+	_ = ctx
+
+	cards, err := p.load()
+	if err != nil {
+		return nil, err
+	}
+
+	wholeYear := !to.Before(from.AddDate(1, 0, 0))
+
+	var (
+		out  []application.CalendarEvent
+		errs []error
+	)
+	for _, c := range cards {
+		var occs []time.Time
+		if c.Birthday != nil {
+			occs = c.Birthday.between(from, to)
+		}
+
+		if len(c.Errs) > 0 {
+			if wholeYear || len(occs) > 0 {
+				errs = append(errs, c.Errs...)
+			}
+			continue
+		}
+		for _, occ := range occs {
+			out = append(out, c.event(occ))
+		}
+	}
+
+	sort.SliceStable(out, func(i, j int) bool {
+		return out[i].StartDate.Before(out[j].StartDate)
+	})
+	if len(errs) > 0 {
+		return out, &application.PartialFetchError{Errs: errs}
+	}
+	return out, nil
+}
+
+func (p Provider) load() ([]card, error) {
+	info, err := os.Stat(p.Path)
+	if err != nil {
+		return nil, fmt.Errorf("vcard: %w", err)
+	}
+
+	if !info.IsDir() {
+		return loadFile(p.Path)
+	}
+
+	var all []card
+	err = filepath.WalkDir(p.Path, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		ext := filepath.Ext(path)
+		if d.IsDir() || !(strings.EqualFold(ext, ".vcf") || strings.EqualFold(ext, ".vcard")) {
+			return nil
+		}
+
+		cards, err := loadFile(path)
+		if err != nil {
+			return err
+		}
+		all = append(all, cards...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return all, nil
+}
+
+func loadFile(path string) ([]card, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("vcard: open %s: %w", path, err)
+	}
+	defer f.Close()
+
+	cards, err := parseCards(f)
+	if err != nil {
+		return nil, fmt.Errorf("vcard: parse %s: %w", path, err)
+	}
+	for _, c := range cards {
+		for i, e := range c.Errs {
+			c.Errs[i] = fmt.Errorf("vcard: %s: %w", path, e)
+		}
+	}
+	return cards, nil
+}
+
+// between returns the dates of the birthday in [from, to).
+func (b birthday) between(from, to time.Time) []time.Time {
+	var out []time.Time
+	for year := from.Year(); year <= to.Year(); year++ {
+		occ := domain.Anniversary(year, b.Month, b.Day, from.Location())
+		if !occ.Before(from) && occ.Before(to) {
+			out = append(out, occ)
+		}
+	}
+	return out
+}
+
+func (c card) event(occ time.Time) application.CalendarEvent {
+	var desc []string
+	if phone := c.preferredPhone(); phone != "" {
+		desc = append(desc, "phone: "+phone)
+	}
+	if note := strings.TrimSpace(c.Note); note != "" {
+		desc = append(desc, "context: "+note)
+	}
+
+	return application.CalendarEvent{
+		ID:          fmt.Sprintf("%s_%s", c.id(), occ.Format("20060102")),
+		Title:       c.name(),
+		Description: strings.Join(desc, "\n"),
+		StartDate:   occ,
+	}
+}
+
+func (c card) name() string {
+	for _, n := range []string{c.FN, c.Nickname, c.N} {
+		if n = strings.TrimSpace(n); n != "" {
+			return n
+		}
+	}
+	return ""
+}
+
+func (c card) id() string {
+	if c.UID != "" {
+		return strings.TrimPrefix(c.UID, "urn:uuid:")
+	}
+	return c.name()
+}
+
+// preferredPhone picks the TEL with the lowest PREF, then a mobile number,
+// then the first one listed. Fax and pager numbers are never picked.
+func (c card) preferredPhone() string {
+	best, bestScore := "", -1
+	for i, t := range c.Phones {
+		if t.has("fax") || t.has("pager") {
+			continue
+		}
+
+		score := len(c.Phones) - i
+		if t.has("cell") || t.has("mobile") || t.has("iphone") {
+			score += 1000
+		}
+		if t.Pref > 0 {
+			score += 100000 - t.Pref
+		}
+
+		if score > bestScore {
+			best, bestScore = t.Number, score
+		}
+	}
+	return best
+}
+
+func (t tel) has(typ string) bool {
+	for _, v := range t.Types {
+		if v == typ {
+			return true
+		}
+	}
+	return false
+}
+
+func startOfDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
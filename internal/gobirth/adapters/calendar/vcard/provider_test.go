@@ -0,0 +1,197 @@
+package vcard
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rafakmp18/gobirth/internal/gobirth/application"
+)
+
+const sampleVCF = `BEGIN:VCARD
+VERSION:3.0
+UID:pepe-1
+FN:Pepe García
+NICKNAME:Pepito
+N:García;Pepe;;;
+BDAY:1990-01-16
+TEL;TYPE=HOME,VOICE:+34910000000
+TEL;TYPE=CELL:+34600111222
+TEL;TYPE=WORK;TYPE=pref:+34 (600) 999-888
+NOTE:colega del gym\, y del k1
+END:VCARD
+BEGIN:VCARD
+VERSION:4.0
+UID:urn:uuid:ana-2
+FN:Ana
+BDAY:--0116
+TEL;VALUE=uri;TYPE="voice,cell":tel:+34-600-333-444
+TEL;VALUE=uri;PREF=1;TYPE=fax:tel:+34600000001
+NOTE:amiga de la uni\nse fue a Berlín
+END:VCARD
+BEGIN:VCARD
+VERSION:4.0
+N:Ruiz;Luis;;;
+item1.TEL;TYPE=cell:+34600555666
+BDAY:20000229
+END:VCARD
+BEGIN:VCARD
+VERSION:3.0
+FN:Sin cumpleaños
+TEL:+34600777888
+END:VCARD
+`
+
+func writeVCF(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestProvider_EventsForDate(t *testing.T) {
+	path := writeVCF(t, t.TempDir(), "contacts.vcf", sampleVCF)
+
+	events, err := Provider{Path: path}.EventsForDate(context.Background(), time.Date(2026, 1, 16, 9, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("expected 2 events, got %d: %+v", len(events), events)
+	}
+
+	pepe, ana := events[0], events[1]
+	if pepe.Title == "Ana" {
+		pepe, ana = ana, pepe
+	}
+
+	if pepe.ID != "pepe-1_20260116" || pepe.Title != "Pepe García" {
+		t.Fatalf("expected Pepe García with ID pepe-1_20260116, got %q %q", pepe.Title, pepe.ID)
+	}
+	if want := "phone: +34600999888\ncontext: colega del gym, y del k1"; pepe.Description != want {
+		t.Fatalf("expected Pepe's description %q, got %q", want, pepe.Description)
+	}
+
+	if ana.ID != "ana-2_20260116" {
+		t.Fatalf("expected Ana's ID ana-2_20260116, got %q", ana.ID)
+	}
+	if want := "phone: +34600333444\ncontext: amiga de la uni\nse fue a Berlín"; ana.Description != want {
+		t.Fatalf("expected Ana's description %q, got %q", want, ana.Description)
+	}
+	if !ana.StartDate.Equal(time.Date(2026, 1, 16, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("expected Ana's start date 2026-01-16, got %v", ana.StartDate)
+	}
+}
+
+func TestProvider_LeapDayAndNameFromN(t *testing.T) {
+	path := writeVCF(t, t.TempDir(), "contacts.vcf", sampleVCF)
+	p := Provider{Path: path}
+
+	events, err := p.EventsForDate(context.Background(), time.Date(2026, 2, 28, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if len(events) != 1 || events[0].Title != "Luis Ruiz" || events[0].Description != "phone: +34600555666" {
+		t.Fatalf("expected Luis Ruiz on Feb 28 2026, got %+v", events)
+	}
+
+	events, err = p.EventsForDate(context.Background(), time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if len(events) != 1 {
+		t.Fatalf("expected Luis on Feb 29 2028, got %+v", events)
+	}
+}
+
+func TestProvider_DirectoryAndRange(t *testing.T) {
+	dir := t.TempDir()
+	writeVCF(t, dir, "a.vcf", sampleVCF)
+	writeVCF(t, dir, "b.VCF", "BEGIN:VCARD\r\nVERSION:3.0\r\nFN:Marta\r\nBDAY:19851231\r\nTEL:+3460\r\n 0123456\r\nEND:VCARD\r\n")
+	writeVCF(t, dir, "ignored.txt", "BEGIN:VCARD\nFN:Nope\nBDAY:--0101\nEND:VCARD\n")
+
+	from := time.Date(2025, 12, 30, 0, 0, 0, 0, time.UTC)
+	events, err := Provider{Path: dir}.EventsBetween(context.Background(), from, from.AddDate(0, 0, 20))
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+
+	var titles []string
+	for _, ev := range events {
+		titles = append(titles, ev.Title)
+	}
+	if len(events) != 3 || events[0].Title != "Marta" || events[0].Description != "phone: +34600123456" {
+		t.Fatalf("expected Marta first of 3 events, got %v", titles)
+	}
+}
+
+func TestProvider_SkipsAndReportsMalformedCards(t *testing.T) {
+	path := writeVCF(t, t.TempDir(), "contacts.vcf", `BEGIN:VCARD
+FN:Pepe
+BDAY:1990-01-16
+TEL:+34600111222
+END:VCARD
+BEGIN:VCARD
+FN:Ana
+BDAY:16/01/1991
+TEL:+34600333444
+END:VCARD
+BEGIN:VCARD
+FN:Luis
+BDAY:--0320
+TEL+34600555666
+END:VCARD
+`)
+	p := Provider{Path: path}
+	from := time.Date(2026, 1, 16, 0, 0, 0, 0, time.UTC)
+
+	// On a day, only the cards with a birthday in it are reported; Ana's
+	// date cannot be read, so she waits for a yearly check.
+	events, err := p.EventsForDate(context.Background(), from)
+	if err != nil || len(events) != 1 || events[0].Title != "Pepe" {
+		t.Fatalf("daily = %+v, %v", events, err)
+	}
+	_, err = p.EventsForDate(context.Background(), time.Date(2026, 3, 20, 0, 0, 0, 0, time.UTC))
+	var partial *application.PartialFetchError
+	if !errors.As(err, &partial) || len(partial.Errs) != 1 || !strings.Contains(partial.Errs[0].Error(), "line 14") {
+		t.Fatalf("Luis's day err = %v", err)
+	}
+
+	events, err = p.EventsBetween(context.Background(), from, from.AddDate(1, 0, 0))
+	if !errors.As(err, &partial) || len(partial.Errs) != 2 {
+		t.Fatalf("yearly err = %v", err)
+	}
+	if msg := partial.Errs[0].Error(); !strings.Contains(msg, path+": line 8: BDAY") {
+		t.Errorf("first error = %q", msg)
+	}
+	if len(events) != 1 || events[0].Title != "Pepe" {
+		t.Errorf("yearly events = %+v", events)
+	}
+}
+
+func TestParseBirthday(t *testing.T) {
+	cases := map[string]birthday{
+		"19900116":             {1990, time.January, 16},
+		"1990-01-16":           {1990, time.January, 16},
+		"1990-01-16T00:00:00Z": {1990, time.January, 16},
+		"--0116":               {0, time.January, 16},
+		"--01-16":              {0, time.January, 16},
+		"--0229":               {0, time.February, 29},
+	}
+	for in, want := range cases {
+		got, err := parseBirthday(in)
+		if err != nil || got != want {
+			t.Fatalf("expected parseBirthday(%q) = %+v, got %+v, %v", in, want, got, err)
+		}
+	}
+
+	if _, err := parseBirthday("sometime in May"); err == nil {
+		t.Fatalf("expected error for free-text BDAY, got nil")
+	}
+}
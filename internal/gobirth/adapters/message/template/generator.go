@@ -3,6 +3,9 @@ package template
 import (
 	"context"
 	"fmt"
+	"strconv"
+	"strings"
+	"text/template"
 
 	"github.com/rafakmp18/gobirth/internal/gobirth/application"
 	"github.com/rafakmp18/gobirth/internal/gobirth/domain"
@@ -10,8 +13,30 @@ import (
 
 type Generator struct {
 	Emoji string
+	// Template is an optional text/template overriding the built-in message.
+	// It can use {{.Name}}, {{.Context}}, {{.Emoji}}, {{.Occasion}},
+	// {{.Years}} and {{.Names}}, and the function ordinal ({{ordinal .Years}}
+	// is "5th"). In a joint message Name already joins all the Names.
+	Template string
+	// Templates overrides Template for single occasions.
+	Templates map[domain.Occasion]string
+	// GroupTemplate is used for group posts instead of the templates
+	// above. The built-in group post leaves the context out, as it is
+	// usually meant for the contact only.
+	GroupTemplate string
 }
 
+type templateData struct {
+	Name     string
+	Context  string
+	Emoji    string
+	Occasion domain.Occasion
+	Years    int
+	Names    []string
+}
+
+var funcs = template.FuncMap{"ordinal": ordinal}
+
 func (g Generator) Generate(ctx context.Context, in application.MessageInput) (domain.GreetingMessage, error) {
 	_ = ctx
 
@@ -20,12 +45,114 @@ func (g Generator) Generate(ctx context.Context, in application.MessageInput) (d
 		emoji = "🎉"
 	}
 
-	var text string
-	if in.Context != "" {
-		text = fmt.Sprintf("¡Feliz cumpleaños, %s! %s\n%s", in.Name, emoji, in.Context)
-	} else {
-		text = fmt.Sprintf("¡Feliz cumpleaños, %s! %s", in.Name, emoji)
+	occasion := in.Occasion
+	if occasion == "" {
+		occasion = domain.OccasionBirthday
+	}
+
+	group := in.Recipient == domain.RecipientGroup
+
+	name := in.Name
+	if len(in.Names) > 1 {
+		name = joinNames(in.Names)
+	}
+
+	text := g.Templates[occasion]
+	if text == "" {
+		text = g.Template
+	}
+	if group {
+		text = g.GroupTemplate
+	}
+	if text != "" {
+		return render(text, templateData{
+			Name:     name,
+			Context:  in.Context,
+			Emoji:    emoji,
+			Occasion: occasion,
+			Years:    in.Years,
+			Names:    in.Names,
+		})
+	}
+
+	msg := fmt.Sprintf("%s %s", headline(occasion, name, in.Years), emoji)
+	if in.Context != "" && !group {
+		msg += "\n" + in.Context
 	}
 
-	return domain.NewGreetingMessage(text), nil
+	return domain.NewGreetingMessage(msg), nil
+}
+
+// headline is the built-in first line for each occasion.
+func headline(occasion domain.Occasion, name string, years int) string {
+	switch occasion {
+	case domain.OccasionBirthday:
+		if years > 0 {
+			return fmt.Sprintf("¡Feliz %dº cumpleaños, %s!", years, name)
+		}
+		return fmt.Sprintf("¡Feliz cumpleaños, %s!", name)
+	case domain.OccasionWeddingAnniversary:
+		if years > 0 {
+			return fmt.Sprintf("¡Feliz %dº aniversario, %s!", years, name)
+		}
+		return fmt.Sprintf("¡Feliz aniversario, %s!", name)
+	case domain.OccasionWorkAnniversary:
+		if years > 0 {
+			return fmt.Sprintf("¡Feliz %dº aniversario en el trabajo, %s!", years, name)
+		}
+		return fmt.Sprintf("¡Feliz aniversario en el trabajo, %s!", name)
+	case domain.OccasionNameDay:
+		return fmt.Sprintf("¡Feliz santo, %s!", name)
+	default:
+		return fmt.Sprintf("¡Feliz %s, %s!", strings.ReplaceAll(string(occasion), "-", " "), name)
+	}
+}
+
+// joinNames lists names the Spanish way: "Ana, Luis y Marta".
+func joinNames(names []string) string {
+	if len(names) < 2 {
+		return strings.Join(names, "")
+	}
+	return strings.Join(names[:len(names)-1], ", ") + " y " + names[len(names)-1]
+}
+
+// Check parses text the way Generate does, so a broken template is caught
+// before the first greeting. It does not run it.
+func Check(text string) error {
+	_, err := parse(text)
+	return err
+}
+
+func parse(text string) (*template.Template, error) {
+	return template.New("greeting").Option("missingkey=error").Funcs(funcs).Parse(text)
+}
+
+func render(text string, data templateData) (domain.GreetingMessage, error) {
+	tmpl, err := parse(text)
+	if err != nil {
+		return domain.GreetingMessage{}, fmt.Errorf("template generator: parse template: %w", err)
+	}
+
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, data); err != nil {
+		return domain.GreetingMessage{}, fmt.Errorf("template generator: render template: %w", err)
+	}
+
+	return domain.NewGreetingMessage(sb.String()), nil
+}
+
+// ordinal writes n in English ordinal form: 1st, 2nd, 3rd, 11th, 22nd...
+func ordinal(n int) string {
+	suffix := "th"
+	if n%100 < 11 || n%100 > 13 {
+		switch n % 10 {
+		case 1:
+			suffix = "st"
+		case 2:
+			suffix = "nd"
+		case 3:
+			suffix = "rd"
+		}
+	}
+	return strconv.Itoa(n) + suffix
 }
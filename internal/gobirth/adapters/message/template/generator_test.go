@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/rafakmp18/gobirth/internal/gobirth/application"
+	"github.com/rafakmp18/gobirth/internal/gobirth/domain"
 )
 
 func TestTemplateGenerator_Generate(t *testing.T) {
@@ -24,3 +25,95 @@ func TestTemplateGenerator_Generate(t *testing.T) {
 		t.Fatalf("expected non-empty message")
 	}
 }
+
+func TestTemplateGenerator_Generate_CustomTemplate(t *testing.T) {
+	g := Generator{Emoji: "🎂", Template: "Happy birthday {{.Name}} {{.Emoji}}"}
+
+	msg, err := g.Generate(context.Background(), application.MessageInput{Name: "Pepe"})
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+
+	if msg.Text() != "Happy birthday Pepe 🎂" {
+		t.Fatalf("unexpected message %q", msg.Text())
+	}
+}
+
+func TestTemplateGenerator_Generate_Occasions(t *testing.T) {
+	g := Generator{Emoji: "🎉"}
+
+	cases := []struct {
+		in   application.MessageInput
+		want string
+	}{
+		{application.MessageInput{Name: "Pepe"}, "¡Feliz cumpleaños, Pepe! 🎉"},
+		{application.MessageInput{Name: "Pepe", Years: 30}, "¡Feliz 30º cumpleaños, Pepe! 🎉"},
+		{application.MessageInput{Name: "Ana y Luis", Occasion: domain.OccasionWeddingAnniversary, Years: 5}, "¡Feliz 5º aniversario, Ana y Luis! 🎉"},
+		{application.MessageInput{Name: "Marta", Occasion: domain.OccasionWorkAnniversary}, "¡Feliz aniversario en el trabajo, Marta! 🎉"},
+		{application.MessageInput{Name: "José", Occasion: domain.OccasionNameDay, Context: "Un abrazo"}, "¡Feliz santo, José! 🎉\nUn abrazo"},
+		{application.MessageInput{Name: "Eva", Occasion: domain.ParseOccasion("graduation")}, "¡Feliz graduation, Eva! 🎉"},
+	}
+	for _, c := range cases {
+		msg, err := g.Generate(context.Background(), c.in)
+		if err != nil {
+			t.Fatalf("%s: %v", c.in.Occasion, err)
+		}
+		if msg.Text() != c.want {
+			t.Errorf("%s: got %q, want %q", c.in.Occasion, msg.Text(), c.want)
+		}
+	}
+}
+
+func TestTemplateGenerator_Generate_OccasionTemplate(t *testing.T) {
+	g := Generator{
+		Template: "Happy birthday {{.Name}}",
+		Templates: map[domain.Occasion]string{
+			domain.OccasionWeddingAnniversary: "Happy {{ordinal .Years}} anniversary, {{.Name}}!",
+		},
+	}
+
+	msg, err := g.Generate(context.Background(), application.MessageInput{
+		Name: "Ana", Occasion: domain.OccasionWeddingAnniversary, Years: 22,
+	})
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if msg.Text() != "Happy 22nd anniversary, Ana!" {
+		t.Fatalf("unexpected message %q", msg.Text())
+	}
+
+	msg, err = g.Generate(context.Background(), application.MessageInput{Name: "Pepe"})
+	if err != nil || msg.Text() != "Happy birthday Pepe" {
+		t.Fatalf("birthday falls back to Template, got %q, %v", msg.Text(), err)
+	}
+}
+
+func TestTemplateGenerator_Generate_GroupPost(t *testing.T) {
+	in := application.MessageInput{Name: "Pepe", Context: "solo para Pepe", Recipient: domain.RecipientGroup}
+
+	msg, err := Generator{Emoji: "🎂", Template: "DM {{.Name}}"}.Generate(context.Background(), in)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if msg.Text() != "¡Feliz cumpleaños, Pepe! 🎂" {
+		t.Fatalf("built-in group post must skip the DM template and the context, got %q", msg.Text())
+	}
+
+	msg, err = Generator{GroupTemplate: "Hoy cumple {{.Name}}, ¡felicitadle!"}.Generate(context.Background(), in)
+	if err != nil || msg.Text() != "Hoy cumple Pepe, ¡felicitadle!" {
+		t.Fatalf("got %q, %v", msg.Text(), err)
+	}
+}
+
+func TestTemplateGenerator_Generate_JointMessage(t *testing.T) {
+	msg, err := Generator{Emoji: "🎉"}.Generate(context.Background(), application.MessageInput{
+		Name:  "Gemelos",
+		Names: []string{"Ana", "Luis", "Marta"},
+	})
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if msg.Text() != "¡Feliz cumpleaños, Ana, Luis y Marta! 🎉" {
+		t.Fatalf("unexpected message %q", msg.Text())
+	}
+}
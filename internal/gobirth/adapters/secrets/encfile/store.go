@@ -0,0 +1,150 @@
+package encfile
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"filippo.io/age"
+
+	"github.com/rafakmp18/gobirth/internal/gobirth/adapters/atomicfile"
+	"github.com/rafakmp18/gobirth/internal/gobirth/application"
+)
+
+// Store keeps all secrets in a single age file encrypted with a passphrase
+// (scrypt). The file can be decrypted by hand with `age -d`.
+type Store struct {
+	Path       string
+	Passphrase string
+
+	mu sync.Mutex
+	// workFactor overrides the scrypt cost; only tests lower it.
+	workFactor int
+}
+
+func New(path, passphrase string) *Store {
+	return &Store{Path: path, Passphrase: passphrase}
+}
+
+func (s *Store) Get(ctx context.Context, key string) ([]byte, error) {
+	_ = ctx
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	secrets, err := s.load()
+	if err != nil {
+		return nil, err
+	}
+
+	v, ok := secrets[key]
+	if !ok {
+		return nil, application.ErrSecretNotFound
+	}
+	return v, nil
+}
+
+func (s *Store) Set(ctx context.Context, key string, value []byte) error {
+	_ = ctx
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	secrets, err := s.load()
+	if err != nil {
+		return err
+	}
+
+	secrets[key] = value
+	return s.save(secrets)
+}
+
+func (s *Store) Delete(ctx context.Context, key string) error {
+	_ = ctx
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	secrets, err := s.load()
+	if err != nil {
+		return err
+	}
+	if _, ok := secrets[key]; !ok {
+		return nil
+	}
+
+	delete(secrets, key)
+	return s.save(secrets)
+}
+
+func (s *Store) load() (map[string][]byte, error) {
+	if s.Passphrase == "" {
+		return nil, errors.New("encrypted secrets: passphrase is empty")
+	}
+
+	b, err := os.ReadFile(s.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return map[string][]byte{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("encrypted secrets: read %s: %w", s.Path, err)
+	}
+
+	id, err := age.NewScryptIdentity(s.Passphrase)
+	if err != nil {
+		return nil, fmt.Errorf("encrypted secrets: %w", err)
+	}
+
+	r, err := age.Decrypt(bytes.NewReader(b), id)
+	if err != nil {
+		return nil, fmt.Errorf("encrypted secrets: decrypt %s (wrong passphrase?): %w", s.Path, err)
+	}
+
+	plain, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("encrypted secrets: decrypt %s: %w", s.Path, err)
+	}
+
+	secrets := map[string][]byte{}
+	if err := json.Unmarshal(plain, &secrets); err != nil {
+		return nil, fmt.Errorf("encrypted secrets: decode %s: %w", s.Path, err)
+	}
+	return secrets, nil
+}
+
+func (s *Store) save(secrets map[string][]byte) error {
+	plain, err := json.Marshal(secrets)
+	if err != nil {
+		return fmt.Errorf("encrypted secrets: encode: %w", err)
+	}
+
+	rcpt, err := age.NewScryptRecipient(s.Passphrase)
+	if err != nil {
+		return fmt.Errorf("encrypted secrets: %w", err)
+	}
+	if s.workFactor > 0 {
+		rcpt.SetWorkFactor(s.workFactor)
+	}
+
+	var buf bytes.Buffer
+	w, err := age.Encrypt(&buf, rcpt)
+	if err != nil {
+		return fmt.Errorf("encrypted secrets: encrypt: %w", err)
+	}
+	if _, err := w.Write(plain); err != nil {
+		return fmt.Errorf("encrypted secrets: encrypt: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("encrypted secrets: encrypt: %w", err)
+	}
+
+	if err := atomicfile.Write(s.Path, buf.Bytes()); err != nil {
+		return fmt.Errorf("encrypted secrets: write: %w", err)
+	}
+	return nil
+}
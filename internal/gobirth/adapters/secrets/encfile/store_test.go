@@ -0,0 +1,47 @@
+package encfile
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rafakmp18/gobirth/internal/gobirth/application"
+)
+
+func TestStore_RoundTripIsEncrypted(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "secrets.age")
+	ctx := context.Background()
+
+	s := New(path, "correct horse battery staple")
+	s.workFactor = 10
+	if err := s.Set(ctx, "google-token", []byte("super-secret")); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if bytes.Contains(raw, []byte("super-secret")) {
+		t.Fatalf("expected secret to be encrypted on disk")
+	}
+
+	v, err := New(path, "correct horse battery staple").Get(ctx, "google-token")
+	if err != nil || string(v) != "super-secret" {
+		t.Fatalf("unexpected value %q, err %v", v, err)
+	}
+
+	if _, err := New(path, "wrong").Get(ctx, "google-token"); err == nil {
+		t.Fatalf("expected error with wrong passphrase")
+	}
+
+	if err := s.Delete(ctx, "google-token"); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if _, err := s.Get(ctx, "google-token"); !errors.Is(err, application.ErrSecretNotFound) {
+		t.Fatalf("expected ErrSecretNotFound, got %v", err)
+	}
+}
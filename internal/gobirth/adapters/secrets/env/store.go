@@ -0,0 +1,55 @@
+package env
+
+import (
+	"context"
+	"os"
+	"strings"
+
+	"github.com/rafakmp18/gobirth/internal/gobirth/application"
+)
+
+const DefaultPrefix = "GOBIRTH_SECRET_"
+
+// Store reads secrets from environment variables, e.g. the key
+// "google-token" from GOBIRTH_SECRET_GOOGLE_TOKEN. It is read-only, which
+// suits containers and CI where secrets are injected by the platform.
+type Store struct {
+	Prefix string
+	// LookupEnv defaults to os.LookupEnv.
+	LookupEnv func(string) (string, bool)
+}
+
+func (s Store) Get(ctx context.Context, key string) ([]byte, error) {
+	_ = ctx
+
+	lookup := s.LookupEnv
+	if lookup == nil {
+		lookup = os.LookupEnv
+	}
+
+	v, ok := lookup(s.VarName(key))
+	if !ok || v == "" {
+		return nil, application.ErrSecretNotFound
+	}
+	return []byte(v), nil
+}
+
+func (s Store) Set(ctx context.Context, key string, value []byte) error {
+	return application.ErrSecretReadOnly
+}
+
+func (s Store) Delete(ctx context.Context, key string) error {
+	return application.ErrSecretReadOnly
+}
+
+// VarName returns the environment variable that holds key.
+func (s Store) VarName(key string) string {
+	prefix := s.Prefix
+	if prefix == "" {
+		prefix = DefaultPrefix
+	}
+
+	name := strings.ToUpper(key)
+	name = strings.NewReplacer("-", "_", ".", "_", "/", "_").Replace(name)
+	return prefix + name
+}
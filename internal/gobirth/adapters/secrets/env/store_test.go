@@ -0,0 +1,31 @@
+package env
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/rafakmp18/gobirth/internal/gobirth/application"
+)
+
+func TestStore_Get_ReadsPrefixedVariable(t *testing.T) {
+	s := Store{LookupEnv: func(k string) (string, bool) {
+		if k == "GOBIRTH_SECRET_GOOGLE_TOKEN" {
+			return "tok", true
+		}
+		return "", false
+	}}
+
+	v, err := s.Get(context.Background(), "google-token")
+	if err != nil || string(v) != "tok" {
+		t.Fatalf("unexpected value %q, err %v", v, err)
+	}
+
+	if _, err := s.Get(context.Background(), "sender-token"); !errors.Is(err, application.ErrSecretNotFound) {
+		t.Fatalf("expected ErrSecretNotFound, got %v", err)
+	}
+
+	if err := s.Set(context.Background(), "x", nil); !errors.Is(err, application.ErrSecretReadOnly) {
+		t.Fatalf("expected ErrSecretReadOnly, got %v", err)
+	}
+}
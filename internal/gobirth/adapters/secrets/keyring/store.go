@@ -0,0 +1,58 @@
+package keyring
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	gokeyring "github.com/zalando/go-keyring"
+
+	"github.com/rafakmp18/gobirth/internal/gobirth/application"
+)
+
+const DefaultService = "gobirth"
+
+// Store keeps secrets in the OS keyring: the Freedesktop Secret Service
+// (GNOME Keyring, KWallet) on Linux, Keychain on macOS and the Credential
+// Manager on Windows.
+type Store struct {
+	Service string
+}
+
+func (s Store) Get(ctx context.Context, key string) ([]byte, error) {
+	_ = ctx
+
+	v, err := gokeyring.Get(s.service(), key)
+	if err != nil {
+		if errors.Is(err, gokeyring.ErrNotFound) {
+			return nil, application.ErrSecretNotFound
+		}
+		return nil, fmt.Errorf("keyring: get %s: %w", key, err)
+	}
+	return []byte(v), nil
+}
+
+func (s Store) Set(ctx context.Context, key string, value []byte) error {
+	_ = ctx
+
+	if err := gokeyring.Set(s.service(), key, string(value)); err != nil {
+		return fmt.Errorf("keyring: set %s: %w", key, err)
+	}
+	return nil
+}
+
+func (s Store) Delete(ctx context.Context, key string) error {
+	_ = ctx
+
+	if err := gokeyring.Delete(s.service(), key); err != nil && !errors.Is(err, gokeyring.ErrNotFound) {
+		return fmt.Errorf("keyring: delete %s: %w", key, err)
+	}
+	return nil
+}
+
+func (s Store) service() string {
+	if s.Service == "" {
+		return DefaultService
+	}
+	return s.Service
+}
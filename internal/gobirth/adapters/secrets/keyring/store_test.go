@@ -0,0 +1,37 @@
+package keyring
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	gokeyring "github.com/zalando/go-keyring"
+
+	"github.com/rafakmp18/gobirth/internal/gobirth/application"
+)
+
+func TestStore_RoundTrip(t *testing.T) {
+	gokeyring.MockInit()
+	s := Store{}
+	ctx := context.Background()
+
+	if _, err := s.Get(ctx, "google-token"); !errors.Is(err, application.ErrSecretNotFound) {
+		t.Fatalf("expected ErrSecretNotFound, got %v", err)
+	}
+
+	if err := s.Set(ctx, "google-token", []byte(`{"access_token":"a"}`)); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+
+	v, err := s.Get(ctx, "google-token")
+	if err != nil || string(v) != `{"access_token":"a"}` {
+		t.Fatalf("unexpected value %q, err %v", v, err)
+	}
+
+	if err := s.Delete(ctx, "google-token"); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if _, err := s.Get(ctx, "google-token"); !errors.Is(err, application.ErrSecretNotFound) {
+		t.Fatalf("expected ErrSecretNotFound after delete, got %v", err)
+	}
+}
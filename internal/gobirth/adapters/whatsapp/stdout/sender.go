@@ -19,14 +19,35 @@ func New(out io.Writer) Sender {
 func (s Sender) SendText(ctx context.Context, to domain.Phone, text string) error {
 	_ = ctx
 
-	out := s.Out
-	if out == nil {
+	return s.write(to.String(), text)
+}
+
+// SendGroupText prints a group post, so the stdout sender is also an
+// application.GroupSender.
+func (s Sender) SendGroupText(ctx context.Context, group domain.Group, text string) error {
+	_ = ctx
+
+	return s.write("group "+group.String(), text)
+}
+
+func (s Sender) write(to, text string) error {
+	if s.Out == nil {
 		return fmt.Errorf("stdout sender: Out writer is nil")
 	}
 
-	_, err := fmt.Fprintf(out, "---- GOBIRTH (DRY WHATSAPP) ----\nTO: %s\nMSG:\n%s\n-------------------------------\n\n",
-		to.String(),
+	_, err := fmt.Fprintf(s.Out, "---- GOBIRTH (DRY WHATSAPP) ----\nTO: %s\nMSG:\n%s\n-------------------------------\n\n",
+		to,
 		text,
 	)
 	return err
 }
+
+// Preview lets the stdout sender act as the dry-run application.Previewer.
+func (s Sender) Preview(ctx context.Context, to domain.Phone, text string) error {
+	return s.SendText(ctx, to, text)
+}
+
+// PreviewGroup is the dry-run counterpart of SendGroupText.
+func (s Sender) PreviewGroup(ctx context.Context, group domain.Group, text string) error {
+	return s.SendGroupText(ctx, group, text)
+}
@@ -31,3 +31,39 @@ func TestSender_SendText_WritesOutput(t *testing.T) {
 		t.Fatalf("expected output to contain message, got:\n%s", out)
 	}
 }
+
+func TestSender_Preview_WritesOutput(t *testing.T) {
+	var buf bytes.Buffer
+	s := New(&buf)
+
+	phone, err := domain.NewPhone("+34600111222")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := s.Preview(context.Background(), phone, "hola"); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+
+	if !strings.Contains(buf.String(), "MSG:\nhola") {
+		t.Fatalf("expected output to contain message, got:\n%s", buf.String())
+	}
+}
+
+func TestSender_SendGroupText_WritesOutput(t *testing.T) {
+	var buf bytes.Buffer
+	s := New(&buf)
+
+	group, err := domain.NewGroup("120363012345678901@g.us")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := s.SendGroupText(context.Background(), group, "hola equipo"); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+
+	if !strings.Contains(buf.String(), "TO: group 120363012345678901@g.us\nMSG:\nhola equipo") {
+		t.Fatalf("expected output to contain the group post, got:\n%s", buf.String())
+	}
+}
@@ -2,9 +2,34 @@ package application
 
 import "time"
 
+// Keys of CalendarEvent.Metadata.
+const (
+	MetaPhone   = "phone"
+	MetaContext = "context"
+	MetaLang    = "lang"
+	// MetaOccasion names what the event celebrates (see domain.ParseOccasion)
+	// and MetaSince the year it started, to count years.
+	MetaOccasion = "occasion"
+	MetaSince    = "since"
+	// MetaPeople lists several recipients, "Ana +34..., Luis +34...".
+	MetaPeople = "people"
+	// MetaGroup is the chat group where the greeting is also posted.
+	MetaGroup = "group"
+)
+
 type CalendarEvent struct {
 	ID          string
 	Title       string
 	Description string
 	StartDate   time.Time
+	// Metadata holds contact data the provider read from structured fields
+	// (MetaPhone, MetaContext, MetaOccasion...). EventParser prefers it over
+	// the description.
+	Metadata map[string]string
+	// Greeted is set by providers that record greetings (GreetingRecorder)
+	// when this occurrence was greeted already, to every recipient.
+	Greeted bool
+	// GreetedTo lists the recipients already greeted on this occurrence,
+	// keyed as in GreetingRecorder, when only some messages went out.
+	GreetedTo []string
 }
@@ -0,0 +1,109 @@
+package application
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	ErrSecretNotFound = errors.New("secret not found")
+	ErrSecretReadOnly = errors.New("secret store is read-only")
+	// ErrAlreadyGreeted marks an event skipped because the calendar records
+	// that it was greeted already, possibly from another machine.
+	ErrAlreadyGreeted = errors.New("already greeted")
+	// ErrGroupsUnsupported is returned for group posts when the sender
+	// cannot post in groups.
+	ErrGroupsUnsupported = errors.New("sender cannot post in groups")
+	// ErrNoPreviewer is returned for dry runs without a Previewer, which
+	// would otherwise report messages as previewed that nobody saw.
+	ErrNoPreviewer = errors.New("dry run has no previewer")
+)
+
+// PartialFetchError is returned by a CalendarProvider when some, but not
+// all, of what it reads failed: sources of a combined calendar, or rows of
+// a contact list. The events returned with it are valid and the run goes on
+// with them; Errs holds one error per failed source or row.
+type PartialFetchError struct {
+	Errs []error
+}
+
+func (e *PartialFetchError) Error() string {
+	msgs := make([]string, 0, len(e.Errs))
+	for _, err := range e.Errs {
+		msgs = append(msgs, err.Error())
+	}
+	return fmt.Sprintf("%d calendar source(s) or entries failed: %s", len(e.Errs), strings.Join(msgs, "; "))
+}
+
+func (e *PartialFetchError) Unwrap() []error {
+	return e.Errs
+}
+
+// StaleFetchError is returned by a caching CalendarProvider that served its
+// last good copy because the upstream calendar failed. The events returned
+// with it are usable; CachedAt tells how old they are.
+type StaleFetchError struct {
+	CachedAt time.Time
+	Err      error
+}
+
+func (e *StaleFetchError) Error() string {
+	return fmt.Sprintf("calendar unavailable, using cache from %s: %v", e.CachedAt.Format(time.RFC3339), e.Err)
+}
+
+func (e *StaleFetchError) Unwrap() error {
+	return e.Err
+}
+
+type Stage string
+
+const (
+	StageFetch    Stage = "fetch"
+	StageParse    Stage = "parse"
+	StageGenerate Stage = "generate"
+	StageSend     Stage = "send"
+	StageRecord   Stage = "record"
+)
+
+// EventError wraps a failure of the daily run with the stage it happened in
+// and the calendar event it belongs to. The cause stays reachable through
+// errors.Is/As, so callers can still match the domain sentinels.
+type EventError struct {
+	Stage   Stage
+	EventID string
+	Title   string
+	Err     error
+}
+
+func (e *EventError) Error() string {
+	if e.EventID == "" && e.Title == "" {
+		return fmt.Sprintf("%s: %v", e.Stage, e.Err)
+	}
+
+	return fmt.Sprintf("%s event %q (%s): %v", e.Stage, e.EventID, e.Title, e.Err)
+}
+
+func (e *EventError) Unwrap() error {
+	return e.Err
+}
+
+func newEventError(stage Stage, ev CalendarEvent, err error) error {
+	return &EventError{
+		Stage:   stage,
+		EventID: ev.ID,
+		Title:   ev.Title,
+		Err:     err,
+	}
+}
+
+// StageOf reports the stage an error was produced in, or "" if err does not
+// carry an EventError.
+func StageOf(err error) Stage {
+	var evErr *EventError
+	if errors.As(err, &evErr) {
+		return evErr.Stage
+	}
+	return ""
+}
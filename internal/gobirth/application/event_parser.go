@@ -1,6 +1,9 @@
 package application
 
 import (
+	"errors"
+	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/rafakmp18/gobirth/internal/gobirth/domain"
@@ -8,56 +11,177 @@ import (
 
 type EventParser struct{}
 
+// Parse builds the contact of an event. Structured Metadata wins over the
+// description, key by key, so a provider may set only some of them. The
+// phone may only be left out when the event lists people or names a group.
 func (EventParser) Parse(e CalendarEvent) (domain.Contact, error) {
 	name := strings.TrimSpace(e.Title)
 	if name == "" {
 		return domain.Contact{}, domain.ErrMissingName
 	}
 
-	phoneRaw, context := parseDescription(e.Description)
+	fields := EventFields(e)
 
-	phone, err := domain.NewPhone(phoneRaw)
+	var group domain.Group
+	if g := fields[MetaGroup]; g != "" {
+		var err error
+		if group, err = domain.NewGroup(g); err != nil {
+			return domain.Contact{}, err
+		}
+	}
+
+	people, err := parsePeople(fields[MetaPeople])
 	if err != nil {
 		return domain.Contact{}, err
 	}
 
-	return domain.NewContact(name, phone, context)
-}
+	phone, err := domain.NewPhone(fields[MetaPhone])
+	if err != nil && !(errors.Is(err, domain.ErrMissingPhone) && (len(people) > 0 || !group.IsZero())) {
+		return domain.Contact{}, err
+	}
 
-func parseDescription(desc string) (phone string, context string) {
-	lines := strings.Split(desc, "\n")
+	var since int
+	if s := fields[MetaSince]; s != "" {
+		since, err = strconv.Atoi(s)
+		if err != nil || since < 1 {
+			return domain.Contact{}, fmt.Errorf("since %q: %w", s, domain.ErrInvalidYear)
+		}
+	}
 
-	var ctxLines []string
-	var inContext bool
+	contact, err := domain.NewContact(name, phone, fields[MetaContext])
+	if err != nil {
+		return domain.Contact{}, err
+	}
+	return contact.WithOccasion(domain.ParseOccasion(fields[MetaOccasion]), since).WithGroup(group).WithPeople(people), nil
+}
 
-	for _, line := range lines {
-		l := strings.TrimSpace(line)
-		if l == "" {
+// parsePeople reads a "people:" value, "Ana +34600111222, Luis +34 600
+// 333 444": comma-separated names, each followed by its phone.
+func parsePeople(value string) ([]domain.Person, error) {
+	if strings.TrimSpace(value) == "" {
+		return nil, nil
+	}
+
+	var people []domain.Person
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
 			continue
 		}
 
-		low := strings.ToLower(l)
+		name, phoneRaw := entry, ""
+		if i := strings.Index(entry, "+"); i >= 0 {
+			name, phoneRaw = entry[:i], domain.NormalizePhone(entry[i:])
+		}
 
-		if strings.HasPrefix(low, "phone:") || strings.HasPrefix(low, "tel:") {
-			inContext = false
-			phone = strings.TrimSpace(l[strings.Index(l, ":")+1:])
+		phone, err := domain.NewPhone(phoneRaw)
+		if err != nil {
+			return nil, fmt.Errorf("people %q: %w", entry, err)
+		}
+		person, err := domain.NewPerson(name, phone)
+		if err != nil {
+			return nil, fmt.Errorf("people %q: %w", entry, err)
+		}
+		people = append(people, person)
+	}
+	return people, nil
+}
+
+// EventFields merges the description fields with the event Metadata, which
+// takes precedence, as Parse reads them.
+func EventFields(e CalendarEvent) map[string]string {
+	fields, _ := ParseDescription(e.Description)
+	for k, v := range e.Metadata {
+		if v = strings.TrimSpace(v); v != "" {
+			fields[k] = v
+		}
+	}
+	return fields
+}
+
+func eventPhone(e CalendarEvent) string {
+	return EventFields(e)[MetaPhone]
+}
+
+// ParseDescription reads the "phone:" (or "tel:"), "people:", "group:",
+// "lang:", "occasion:", "since:" and "context:" lines of an event
+// description; context continues over the following lines until another
+// known key. A "group:" line whose value is not a group ID is plain text.
+// It returns the fields found, keyed like CalendarEvent.Metadata, and the
+// description without those lines.
+func ParseDescription(desc string) (fields map[string]string, rest string) {
+	fields = map[string]string{}
+
+	var ctxLines, restLines []string
+	var inContext bool
+
+	for _, line := range strings.Split(desc, "\n") {
+		l := strings.TrimSpace(line)
+		if l == "" {
 			continue
 		}
 
-		if strings.HasPrefix(low, "context:") {
+		key, val, isKey := descriptionKey(l)
+		switch {
+		case key == MetaContext:
 			inContext = true
-			val := strings.TrimSpace(l[strings.Index(l, ":")+1:])
 			if val != "" {
 				ctxLines = append(ctxLines, val)
 			}
-			continue
-		}
-
-		if inContext {
+		case key != "":
+			inContext = false
+			if val != "" {
+				fields[key] = val
+			}
+		case isKey:
+			// A line gobirth writes but does not read, e.g. "greeted:".
+			inContext = false
+			restLines = append(restLines, l)
+		case inContext:
 			ctxLines = append(ctxLines, l)
+		default:
+			restLines = append(restLines, l)
 		}
 	}
 
-	context = strings.TrimSpace(strings.Join(ctxLines, "\n"))
-	return phone, context
+	if context := strings.TrimSpace(strings.Join(ctxLines, "\n")); context != "" {
+		fields[MetaContext] = context
+	}
+	return fields, strings.Join(restLines, "\n")
+}
+
+// descriptionKey recognises the "key: value" lines of a description. key is
+// the field the line sets, or "" with isKey set for lines that only end a
+// multi-line context.
+func descriptionKey(line string) (key, val string, isKey bool) {
+	name, val, ok := strings.Cut(line, ":")
+	if !ok {
+		return "", "", false
+	}
+	val = strings.TrimSpace(val)
+
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "phone", "tel":
+		return MetaPhone, val, true
+	case "people":
+		return MetaPeople, val, true
+	case "group":
+		// Free text such as "Group: work friends" is no group ID; the line
+		// is read like any unknown one.
+		if _, err := domain.NewGroup(val); val != "" && err != nil {
+			return "", "", false
+		}
+		return MetaGroup, val, true
+	case "lang":
+		return MetaLang, val, true
+	case "occasion":
+		return MetaOccasion, val, true
+	case "since":
+		return MetaSince, val, true
+	case "context":
+		return MetaContext, val, true
+	case "greeted":
+		return "", "", true
+	}
+	return "", "", false
 }
@@ -1,6 +1,12 @@
 package application
 
-import "testing"
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/rafakmp18/gobirth/internal/gobirth/domain"
+)
 
 func TestEventParser_Parse_OK_WithContext(t *testing.T) {
 	p := EventParser{}
@@ -67,3 +73,116 @@ func TestEventParser_Parse_Fails_WhenMissingPhone(t *testing.T) {
 		t.Fatalf("expected error, got nil")
 	}
 }
+
+func TestEventParser_Parse_PrefersMetadata(t *testing.T) {
+	ev := CalendarEvent{
+		Title:       "Pepe",
+		Description: "phone: +34600000000\ncontext: del gym",
+		Metadata:    map[string]string{MetaPhone: "+34600111222", MetaContext: ""},
+	}
+
+	c, err := EventParser{}.Parse(ev)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if c.Phone().String() != "+34600111222" {
+		t.Fatalf("expected the metadata phone, got %q", c.Phone().String())
+	}
+	if c.Context() != "del gym" {
+		t.Fatalf("expected the description context when metadata has none, got %q", c.Context())
+	}
+}
+
+func TestParseDescription_KnownKeysEndContext(t *testing.T) {
+	fields, rest := ParseDescription("Cumple de Pepe\nphone: +34600111222\ncontext: del gym\ny del k1\ngreeted: 2026-01-16 via whatsapp\nlang: es")
+
+	if fields[MetaPhone] != "+34600111222" || fields[MetaLang] != "es" || fields[MetaContext] != "del gym\ny del k1" {
+		t.Fatalf("unexpected fields %v", fields)
+	}
+	if rest != "Cumple de Pepe\ngreeted: 2026-01-16 via whatsapp" {
+		t.Fatalf("unexpected rest %q", rest)
+	}
+}
+
+func TestEventParser_Parse_Occasion(t *testing.T) {
+	p := EventParser{}
+
+	ev := CalendarEvent{
+		ID:          "1",
+		Title:       "Ana y Luis",
+		Description: "phone: +34600111222\noccasion: anniversary\nsince: 2021\ncontext: boda en Sevilla",
+	}
+
+	c, err := p.Parse(ev)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if c.Occasion() != domain.OccasionWeddingAnniversary {
+		t.Fatalf("expected wedding anniversary, got %q", c.Occasion())
+	}
+	if got := c.Years(time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)); got != 5 {
+		t.Fatalf("expected 5 years, got %d", got)
+	}
+	if c.Context() != "boda en Sevilla" {
+		t.Fatalf("unexpected context %q", c.Context())
+	}
+
+	ev.Description = "phone: +34600111222\nsince: soon"
+	if _, err := p.Parse(ev); !errors.Is(err, domain.ErrInvalidYear) {
+		t.Fatalf("expected ErrInvalidYear, got %v", err)
+	}
+}
+
+func TestEventParser_Parse_GroupWithoutPhone(t *testing.T) {
+	p := EventParser{}
+
+	c, err := p.Parse(CalendarEvent{ID: "1", Title: "Pepe", Description: "group: 120363012345678901@g.us"})
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if !c.Phone().IsZero() || c.Group().String() != "120363012345678901@g.us" {
+		t.Fatalf("unexpected contact %+v", c)
+	}
+
+	c, err = p.Parse(CalendarEvent{ID: "2", Title: "Pepe", Description: "phone: +34600111222\ncontext: del gym\nGroup: work friends"})
+	if err != nil {
+		t.Fatalf("expected free-text group line to be ignored, got %v", err)
+	}
+	if !c.Group().IsZero() || c.Context() != "del gym\nGroup: work friends" {
+		t.Fatalf("expected no group and the line kept in context, got %q %q", c.Group(), c.Context())
+	}
+
+	ev := CalendarEvent{ID: "3", Title: "Pepe", Description: "phone: +34600111222", Metadata: map[string]string{MetaGroup: "my team"}}
+	if _, err := p.Parse(ev); !errors.Is(err, domain.ErrInvalidGroup) {
+		t.Fatalf("expected ErrInvalidGroup for structured metadata, got %v", err)
+	}
+}
+
+func TestEventParser_Parse_People(t *testing.T) {
+	p := EventParser{}
+
+	c, err := p.Parse(CalendarEvent{
+		ID:          "1",
+		Title:       "Gemelos García",
+		Description: "people: Ana +34600111222, Luis +34 600 333 444",
+	})
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+
+	people := c.People()
+	if len(people) != 2 {
+		t.Fatalf("expected 2 people, got %d", len(people))
+	}
+	if people[0].Name() != "Ana" || people[0].Phone().String() != "+34600111222" {
+		t.Errorf("first person = %s %s", people[0].Name(), people[0].Phone())
+	}
+	if people[1].Name() != "Luis" || people[1].Phone().String() != "+34600333444" {
+		t.Errorf("second person = %s %s", people[1].Name(), people[1].Phone())
+	}
+
+	_, err = p.Parse(CalendarEvent{ID: "2", Title: "Gemelos", Description: "people: Ana +34600111222, Luis"})
+	if !errors.Is(err, domain.ErrMissingPhone) {
+		t.Fatalf("expected ErrMissingPhone for Luis, got %v", err)
+	}
+}
@@ -0,0 +1,126 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rafakmp18/gobirth/internal/gobirth/domain"
+)
+
+// ListEvents fetches and parses the calendar events of a date range without
+// generating or sending anything. It backs the "upcoming" and "validate"
+// commands.
+type ListEvents struct {
+	Calendar CalendarProvider
+	Parser   EventParser
+}
+
+type EventListing struct {
+	Date        time.Time
+	EventID     string
+	ContactName string
+	Phone       string
+	Group       string
+	Context     string
+	Occasion    domain.Occasion
+	Years       int
+	Err         error
+}
+
+// Run returns the listings sorted by date. When only some calendar sources
+// failed, or the events came from a stale cache, the listings come back
+// together with a fetch-stage EventError wrapping the PartialFetchError or
+// StaleFetchError.
+func (useCase ListEvents) Run(ctx context.Context, from time.Time, days int) ([]EventListing, error) {
+	if days <= 0 {
+		days = 1
+	}
+
+	start := startOfDay(from)
+	end := start.AddDate(0, 0, days)
+
+	events, err := EventsBetween(ctx, useCase.Calendar, start, end)
+	var (
+		partial *PartialFetchError
+		stale   *StaleFetchError
+	)
+	if err != nil && !errors.As(err, &partial) && !errors.As(err, &stale) {
+		return nil, &EventError{Stage: StageFetch, Err: err}
+	}
+
+	out := make([]EventListing, 0, len(events))
+	for _, ev := range events {
+		listing := EventListing{
+			Date:        startOfDay(ev.StartDate),
+			EventID:     ev.ID,
+			ContactName: strings.TrimSpace(ev.Title),
+		}
+
+		contact, err := useCase.Parser.Parse(ev)
+		if err != nil {
+			phoneRaw := eventPhone(ev)
+			listing.Phone = domain.MaskPhone(phoneRaw)
+			listing.Err = newEventError(StageParse, ev, err)
+		} else {
+			listing.ContactName = contact.Name()
+			var phones []string
+			for _, p := range contact.People() {
+				phones = append(phones, p.Phone().Masked())
+			}
+			listing.Phone = strings.Join(phones, ", ")
+			listing.Group = contact.Group().String()
+			listing.Context = contact.Context()
+			listing.Occasion = contact.Occasion()
+			listing.Years = contact.Years(listing.Date)
+		}
+
+		out = append(out, listing)
+	}
+
+	sort.SliceStable(out, func(i, j int) bool {
+		return out[i].Date.Before(out[j].Date)
+	})
+
+	if err != nil {
+		return out, &EventError{Stage: StageFetch, Err: err}
+	}
+	return out, nil
+}
+
+// EventsBetween returns the events of cal in [from, to). It uses the
+// provider's range query when available and falls back to one EventsForDate
+// call per day otherwise. Adapters that wrap another provider use it too.
+func EventsBetween(ctx context.Context, cal CalendarProvider, from, to time.Time) ([]CalendarEvent, error) {
+	if rp, ok := cal.(CalendarRangeProvider); ok {
+		return rp.EventsBetween(ctx, from, to)
+	}
+
+	var out []CalendarEvent
+	for day := from; day.Before(to); day = day.AddDate(0, 0, 1) {
+		events, err := cal.EventsForDate(ctx, day)
+		if err != nil {
+			return nil, err
+		}
+		for _, ev := range events {
+			if ev.StartDate.IsZero() {
+				ev.StartDate = day
+			}
+			out = append(out, ev)
+		}
+	}
+	return out, nil
+}
+
+// EventsIn keeps the events starting in [from, to).
+func EventsIn(events []CalendarEvent, from, to time.Time) []CalendarEvent {
+	var out []CalendarEvent
+	for _, ev := range events {
+		if !ev.StartDate.Before(from) && ev.StartDate.Before(to) {
+			out = append(out, ev)
+		}
+	}
+	return out
+}
@@ -0,0 +1,51 @@
+package application
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/rafakmp18/gobirth/internal/gobirth/domain"
+)
+
+type dayCalendar struct {
+	byDay map[string][]CalendarEvent
+	calls int
+}
+
+func (d *dayCalendar) EventsForDate(ctx context.Context, date time.Time) ([]CalendarEvent, error) {
+	d.calls++
+	return d.byDay[date.Format("2006-01-02")], nil
+}
+
+func TestListEvents_CollectsRangeAndReportsParseErrors(t *testing.T) {
+	from := time.Date(2026, 1, 16, 9, 0, 0, 0, time.UTC)
+
+	cal := &dayCalendar{byDay: map[string][]CalendarEvent{
+		"2026-01-18": {{ID: "2", Title: "Ana", Description: "phone: 600"}},
+		"2026-01-16": {{ID: "1", Title: "Pepe", Description: "phone: +34600111222"}},
+		"2026-02-01": {{ID: "3", Title: "Fuera", Description: "phone: +34600111333"}},
+	}}
+
+	got, err := ListEvents{Calendar: cal, Parser: EventParser{}}.Run(context.Background(), from, 7)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+
+	if cal.calls != 7 {
+		t.Fatalf("expected one lookup per day, got %d", cal.calls)
+	}
+	if len(got) != 2 {
+		t.Fatalf("expected 2 listings, got %d", len(got))
+	}
+	if got[0].EventID != "1" || got[0].Err != nil || got[0].Phone != "+34******222" {
+		t.Fatalf("unexpected first listing: %+v", got[0])
+	}
+	if got[1].EventID != "2" || !errors.Is(got[1].Err, domain.ErrInvalidPhone) {
+		t.Fatalf("expected invalid phone for event 2, got %+v", got[1])
+	}
+	if got[1].Date.Format("2006-01-02") != "2026-01-18" {
+		t.Fatalf("expected listing date 2026-01-18, got %s", got[1].Date)
+	}
+}
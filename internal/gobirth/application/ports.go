@@ -11,10 +11,25 @@ type CalendarProvider interface {
 	EventsForDate(ctx context.Context, date time.Time) ([]CalendarEvent, error)
 }
 
+// CalendarRangeProvider is optionally implemented by providers that can fetch
+// a date range in a single call. from is inclusive, to is exclusive.
+type CalendarRangeProvider interface {
+	EventsBetween(ctx context.Context, from, to time.Time) ([]CalendarEvent, error)
+}
+
 type MessageInput struct {
-	Name    string
-	Context string
-	Date    time.Time
+	Name     string
+	Context  string
+	Date     time.Time
+	Occasion domain.Occasion
+	// Years is the count celebrated, e.g. 5 for a fifth anniversary; 0 when
+	// unknown.
+	Years int
+	// Recipient is RecipientGroup when the message is posted in a group.
+	Recipient domain.RecipientKind
+	// Names lists everyone a joint message is for; empty when the message
+	// greets Name alone.
+	Names []string
 }
 
 type MessageGenerator interface {
@@ -25,6 +40,41 @@ type WhatsAppSender interface {
 	SendText(ctx context.Context, to domain.Phone, text string) error
 }
 
+// GroupSender is optionally implemented by senders that can post in a chat
+// group, for events with a "group:" line.
+type GroupSender interface {
+	SendGroupText(ctx context.Context, group domain.Group, text string) error
+}
+
+// Previewer renders a greeting without delivering it. It is the only output
+// used when RunDailyGreetings.DryRun is set, so implementations must not have
+// side effects on the recipient.
+type Previewer interface {
+	Preview(ctx context.Context, to domain.Phone, text string) error
+}
+
+// GroupPreviewer is the dry-run counterpart of GroupSender.
+type GroupPreviewer interface {
+	PreviewGroup(ctx context.Context, group domain.Group, text string) error
+}
+
+// SecretStore keeps credentials such as OAuth tokens or sender API keys.
+// Get returns ErrSecretNotFound when key has no value.
+type SecretStore interface {
+	Get(ctx context.Context, key string) ([]byte, error)
+	Set(ctx context.Context, key string, value []byte) error
+	Delete(ctx context.Context, key string) error
+}
+
+// GreetingRecorder is optionally implemented by calendar providers that can
+// write back to the event once a greeting was sent, so that a later run on
+// any machine sees CalendarEvent.GreetedTo and does not send it again. It is
+// called once per message; to is the recipient's phone in E.164 form or
+// "group:" followed by the group ID.
+type GreetingRecorder interface {
+	RecordGreeting(ctx context.Context, ev CalendarEvent, date time.Time, channel, to string) error
+}
+
 type Clock interface {
 	Now() time.Time
 }
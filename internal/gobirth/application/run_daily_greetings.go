@@ -2,6 +2,8 @@ package application
 
 import (
 	"context"
+	"errors"
+	"strings"
 	"time"
 
 	"github.com/rafakmp18/gobirth/internal/gobirth/domain"
@@ -12,17 +14,34 @@ type RunDailyGreetings struct {
 	Parser    EventParser
 	Generator MessageGenerator
 	Sender    WhatsAppSender
+	Previewer Previewer
 	Clock     Clock
+	// Recorder, when set, is told about every greeting sent.
+	Recorder  GreetingRecorder
 	MaxPerRun int
 	DryRun    bool
+	// Joint sends the people of an event one shared message naming all of
+	// them instead of one message each.
+	Joint bool
 }
 
+// RunResult counts events in Total and messages in Sent, Skipped and
+// Failed: an event with a phone and a group makes two messages.
 type RunResult struct {
 	Total   int
 	Sent    int
 	Skipped int
 	Failed  int
 	Errors  []error
+	Items   []ItemResult
+
+	// FromCache is set when the events came from a cache because the
+	// calendar was unreachable; CacheAge is how old that copy was.
+	FromCache bool
+	CacheAge  time.Duration
+	// Warnings are problems that did not stop the run, such as the upstream
+	// error behind a cached fetch.
+	Warnings []error
 }
 
 func (useCase RunDailyGreetings) Run(ctx context.Context) RunResult {
@@ -30,68 +49,264 @@ func (useCase RunDailyGreetings) Run(ctx context.Context) RunResult {
 	date := startOfDay(now)
 
 	events, err := useCase.Calendar.EventsForDate(ctx, date)
-	if err != nil {
-		return RunResult{Failed: 1, Errors: []error{err}}
+	var (
+		partial *PartialFetchError
+		stale   *StaleFetchError
+	)
+	if err != nil && !errors.As(err, &partial) && !errors.As(err, &stale) {
+		return RunResult{Failed: 1, Errors: []error{&EventError{Stage: StageFetch, Err: err}}}
 	}
 
 	res := RunResult{Total: len(events)}
 
+	if stale != nil {
+		res.FromCache = true
+		res.CacheAge = now.Sub(stale.CachedAt)
+		res.Warnings = append(res.Warnings, &EventError{Stage: StageFetch, Err: stale})
+	}
+
+	// Greet whoever the healthy sources returned, but count every failed
+	// source so the run still reports a failure.
+	if partial != nil {
+		for _, srcErr := range partial.Errs {
+			res.Failed++
+			res.Errors = append(res.Errors, &EventError{Stage: StageFetch, Err: srcErr})
+		}
+	}
+
 	limit := useCase.MaxPerRun
 	if limit <= 0 || limit > len(events) {
 		limit = len(events)
 	}
 
 	for i := 0; i < limit; i++ {
-		ev := events[i]
+		for _, item := range useCase.process(ctx, events[i], date) {
+			res.record(item)
+		}
+	}
 
-		contact, err := useCase.Parser.Parse(ev)
-		if err != nil {
-			res.Failed++
-			res.Errors = append(res.Errors, err)
+	for _, ev := range events[limit:] {
+		res.record(skippedItem(ev))
+	}
+
+	return res
+}
+
+// process greets the contact of ev: a direct message to each of its people
+// and a post when it names a group, one item each. With Joint, people
+// listed together share one message naming all of them.
+func (useCase RunDailyGreetings) process(ctx context.Context, ev CalendarEvent, date time.Time) []ItemResult {
+	started := time.Now()
+	item := ItemResult{
+		EventID:     ev.ID,
+		ContactName: strings.TrimSpace(ev.Title),
+		Channel:     ChannelWhatsApp,
+	}
+
+	if ev.Greeted && !useCase.DryRun {
+		phoneRaw := eventPhone(ev)
+		item.Phone = domain.MaskPhone(phoneRaw)
+		return []ItemResult{finishItem(item, started, OutcomeSkipped, ErrAlreadyGreeted)}
+	}
+
+	contact, err := useCase.Parser.Parse(ev)
+	if err != nil {
+		phoneRaw := eventPhone(ev)
+		item.Phone = domain.MaskPhone(phoneRaw)
+		return []ItemResult{finishItem(item, started, OutcomeFailed, newEventError(StageParse, ev, err))}
+	}
+	item.ContactName = contact.Name()
+
+	people := contact.People()
+	var names []string
+	if len(people) > 1 {
+		for _, p := range people {
+			names = append(names, p.Name())
+		}
+	}
+
+	in := MessageInput{
+		Name:     contact.Name(),
+		Context:  contact.Context(),
+		Date:     date,
+		Occasion: contact.Occasion(),
+		Years:    contact.Years(date),
+	}
+
+	var (
+		items    []ItemResult
+		shared   = useCase.Joint && len(names) > 0
+		joint    string
+		jointErr error
+	)
+	if shared {
+		direct := in
+		direct.Recipient = domain.RecipientDirect
+		direct.Names = names
+		joint, jointErr = useCase.generate(ctx, ev, direct)
+	}
+
+	for _, person := range people {
+		started := time.Now()
+		direct := item
+		direct.ContactName = person.Name()
+		direct.Phone = person.Phone().Masked()
+		to := recipient{kind: domain.RecipientDirect, phone: person.Phone()}
+		if useCase.greeted(ev, to) {
+			items = append(items, finishItem(direct, started, OutcomeSkipped, ErrAlreadyGreeted))
 			continue
 		}
 
-		msg, err := useCase.Generator.Generate(ctx, MessageInput{
-			Name:    contact.Name(),
-			Context: contact.Context(),
-			Date:    date,
-		})
+		text, err := joint, jointErr
+		if !shared {
+			personal := in
+			personal.Name = person.Name()
+			personal.Recipient = domain.RecipientDirect
+			text, err = useCase.generate(ctx, ev, personal)
+		}
 		if err != nil {
-			res.Failed++
-			res.Errors = append(res.Errors, err)
+			items = append(items, finishItem(direct, started, OutcomeFailed, err))
 			continue
 		}
+		items = append(items, useCase.record(ctx, ev, date, to, useCase.deliver(ctx, ev, direct, to, text, started)))
+	}
 
-		if useCase.DryRun {
-			if err := useCase.Sender.SendText(ctx, contact.Phone(), msg.Text()); err != nil {
-				res.Failed++
-				res.Errors = append(res.Errors, err)
-				continue
-			}
+	if !contact.Group().IsZero() {
+		started := time.Now()
+		post := item
+		post.Group = contact.Group().String()
+		to := recipient{kind: domain.RecipientGroup, group: contact.Group()}
+		if useCase.greeted(ev, to) {
+			return append(items, finishItem(post, started, OutcomeSkipped, ErrAlreadyGreeted))
+		}
 
-			res.Skipped++
-			continue
+		group := in
+		group.Recipient = domain.RecipientGroup
+		group.Names = names
+		text, err := useCase.generate(ctx, ev, group)
+		if err != nil {
+			items = append(items, finishItem(post, started, OutcomeFailed, err))
+		} else {
+			items = append(items, useCase.record(ctx, ev, date, to, useCase.deliver(ctx, ev, post, to, text, started)))
+		}
+	}
+
+	return items
+}
+
+// greeted reports whether an earlier run already reached to on ev.
+func (useCase RunDailyGreetings) greeted(ev CalendarEvent, to recipient) bool {
+	if useCase.DryRun {
+		return false
+	}
+	for _, key := range ev.GreetedTo {
+		if key == to.key() {
+			return true
 		}
+	}
+	return false
+}
 
-		if err := useCase.Sender.SendText(ctx, contact.Phone(), msg.Text()); err != nil {
-			res.Failed++
-			res.Errors = append(res.Errors, err)
-			continue
+// record tells the Recorder about a sent message, so that a later run
+// retrying the event skips this recipient. The greeting is out by then;
+// failing to record it is only a warning.
+func (useCase RunDailyGreetings) record(ctx context.Context, ev CalendarEvent, date time.Time, to recipient, item ItemResult) ItemResult {
+	if useCase.Recorder == nil || item.Outcome != OutcomeSent {
+		return item
+	}
+	if err := useCase.Recorder.RecordGreeting(ctx, ev, date, item.Channel, to.key()); err != nil {
+		item.Err = newEventError(StageRecord, ev, err)
+	}
+	return item
+}
+
+// recipient is where one message goes: a phone or a group.
+type recipient struct {
+	kind  domain.RecipientKind
+	phone domain.Phone
+	group domain.Group
+}
+
+// key names the recipient in GreetingRecorder calls.
+func (to recipient) key() string {
+	if to.kind == domain.RecipientGroup {
+		return "group:" + to.group.String()
+	}
+	return to.phone.String()
+}
+
+func (useCase RunDailyGreetings) generate(ctx context.Context, ev CalendarEvent, in MessageInput) (string, error) {
+	msg, err := useCase.Generator.Generate(ctx, in)
+	if err != nil {
+		return "", newEventError(StageGenerate, ev, err)
+	}
+	return msg.Text(), nil
+}
+
+// deliver sends, or previews, one message.
+func (useCase RunDailyGreetings) deliver(ctx context.Context, ev CalendarEvent, item ItemResult, to recipient, text string, started time.Time) ItemResult {
+	item.Message = text
+
+	// Dry runs never reach the Sender, whatever adapter is wired in.
+	if useCase.DryRun {
+		if err := useCase.preview(ctx, to, text); err != nil {
+			return finishItem(item, started, OutcomeFailed, newEventError(StageSend, ev, err))
 		}
+		return finishItem(item, started, OutcomePreviewed, nil)
+	}
 
-		res.Sent++
+	item.Attempts++
+	if err := useCase.send(ctx, to, text); err != nil {
+		return finishItem(item, started, OutcomeFailed, newEventError(StageSend, ev, err))
 	}
+	return finishItem(item, started, OutcomeSent, nil)
+}
 
-	if len(events) > limit {
-		res.Skipped += len(events) - limit
+func (useCase RunDailyGreetings) send(ctx context.Context, to recipient, text string) error {
+	if to.kind != domain.RecipientGroup {
+		return useCase.Sender.SendText(ctx, to.phone, text)
+	}
+	gs, ok := useCase.Sender.(GroupSender)
+	if !ok {
+		return ErrGroupsUnsupported
 	}
+	return gs.SendGroupText(ctx, to.group, text)
+}
 
-	return res
+func (useCase RunDailyGreetings) preview(ctx context.Context, to recipient, text string) error {
+	if useCase.Previewer == nil {
+		return ErrNoPreviewer
+	}
+	if to.kind != domain.RecipientGroup {
+		return useCase.Previewer.Preview(ctx, to.phone, text)
+	}
+	gp, ok := useCase.Previewer.(GroupPreviewer)
+	if !ok {
+		return ErrGroupsUnsupported
+	}
+	return gp.PreviewGroup(ctx, to.group, text)
+}
+
+func finishItem(item ItemResult, started time.Time, outcome Outcome, err error) ItemResult {
+	item.Outcome = outcome
+	item.Err = err
+	item.Duration = time.Since(started)
+	return item
+}
+
+func skippedItem(ev CalendarEvent) ItemResult {
+	phoneRaw := eventPhone(ev)
+
+	return ItemResult{
+		EventID:     ev.ID,
+		ContactName: strings.TrimSpace(ev.Title),
+		Phone:       domain.MaskPhone(phoneRaw),
+		Channel:     ChannelWhatsApp,
+		Outcome:     OutcomeSkipped,
+	}
 }
 
 func startOfDay(t time.Time) time.Time {
 	y, m, d := t.Date()
 	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
 }
-
-var _ = domain.ErrMissingName
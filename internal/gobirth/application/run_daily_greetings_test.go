@@ -2,6 +2,8 @@ package application
 
 import (
 	"context"
+	"errors"
+	"strings"
 	"testing"
 	"time"
 
@@ -53,6 +55,22 @@ func (f *fakeSender) SendText(ctx context.Context, to domain.Phone, text string)
 	return nil
 }
 
+type fakePreviewer struct {
+	previewed []string
+}
+
+func (f *fakePreviewer) Preview(ctx context.Context, to domain.Phone, text string) error {
+	f.previewed = append(f.previewed, to.String())
+	return nil
+}
+
+type failingSender struct{ t *testing.T }
+
+func (f failingSender) SendText(ctx context.Context, to domain.Phone, text string) error {
+	f.t.Fatalf("sender must not be called, got message to %s", to.String())
+	return nil
+}
+
 func TestRunDailyGreetings_SendsMessages(t *testing.T) {
 	now := time.Date(2026, 1, 16, 9, 0, 0, 0, time.UTC)
 
@@ -98,14 +116,16 @@ func TestRunDailyGreetings_DryRun_PreviewsMessage(t *testing.T) {
 	}
 
 	sender := &fakeSender{}
+	previewer := &fakePreviewer{}
 	uc := RunDailyGreetings{
 		Calendar:  cal,
 		Parser:    EventParser{},
 		Generator: fakeGenerator{text: "🎉"},
 		Sender:    sender,
+		Previewer: previewer,
 		Clock:     fakeClock{t: now},
-		MaxPerRun:  10,
-		DryRun:     true,
+		MaxPerRun: 10,
+		DryRun:    true,
 	}
 
 	res := uc.Run(context.Background())
@@ -116,11 +136,40 @@ func TestRunDailyGreetings_DryRun_PreviewsMessage(t *testing.T) {
 	if res.Skipped != 1 {
 		t.Fatalf("expected skipped 1, got %d", res.Skipped)
 	}
-	if len(sender.sent) != 1 {
-		t.Fatalf("expected sender to be called once for preview, got %d", len(sender.sent))
+	if len(previewer.previewed) != 1 {
+		t.Fatalf("expected previewer to be called once, got %d", len(previewer.previewed))
+	}
+	if len(sender.sent) != 0 {
+		t.Fatalf("expected sender not to be called in dry-run, got %d", len(sender.sent))
 	}
 }
 
+func TestRunDailyGreetings_DryRun_NeverCallsSender(t *testing.T) {
+	now := time.Date(2026, 1, 16, 9, 0, 0, 0, time.UTC)
+
+	cal := fakeCalendar{
+		events: []CalendarEvent{
+			{ID: "1", Title: "Pepe", Description: "phone: +34600111222", StartDate: now},
+			{ID: "2", Title: "Ana", Description: "phone: +34600333444", StartDate: now},
+		},
+	}
+
+	uc := RunDailyGreetings{
+		Calendar:  cal,
+		Parser:    EventParser{},
+		Generator: fakeGenerator{text: "🎉"},
+		Sender:    failingSender{t: t},
+		Previewer: &fakePreviewer{},
+		Clock:     fakeClock{t: now},
+		DryRun:    true,
+	}
+
+	res := uc.Run(context.Background())
+
+	if res.Failed != 0 || res.Skipped != 2 {
+		t.Fatalf("expected 2 previewed without failures, got %+v", res)
+	}
+}
 
 func TestRunDailyGreetings_RespectsMaxPerRun(t *testing.T) {
 	now := time.Date(2026, 1, 16, 9, 0, 0, 0, time.UTC)
@@ -156,3 +205,458 @@ func TestRunDailyGreetings_RespectsMaxPerRun(t *testing.T) {
 		t.Fatalf("expected sender to send 2 messages, got %d", len(sender.sent))
 	}
 }
+
+func TestRunDailyGreetings_ReportsPerItemResults(t *testing.T) {
+	now := time.Date(2026, 1, 16, 9, 0, 0, 0, time.UTC)
+
+	cal := fakeCalendar{
+		events: []CalendarEvent{
+			{ID: "1", Title: "Pepe", Description: "phone: +34600111222", StartDate: now},
+			{ID: "2", Title: "Ana", Description: "phone: 600333444", StartDate: now},
+			{ID: "3", Title: "Luis", Description: "phone: +34600555666", StartDate: now},
+		},
+	}
+
+	sender := &fakeSender{}
+	uc := RunDailyGreetings{
+		Calendar:  cal,
+		Parser:    EventParser{},
+		Generator: fakeGenerator{text: "🎉"},
+		Sender:    sender,
+		Clock:     fakeClock{t: now},
+		MaxPerRun: 2,
+		DryRun:    false,
+	}
+
+	res := uc.Run(context.Background())
+
+	if len(res.Items) != 3 {
+		t.Fatalf("expected 3 items, got %d", len(res.Items))
+	}
+
+	sent := res.Items[0]
+	if sent.EventID != "1" || sent.Outcome != OutcomeSent {
+		t.Fatalf("expected event 1 sent, got %+v", sent)
+	}
+	if sent.Phone != "+34******222" {
+		t.Fatalf("expected masked phone, got %q", sent.Phone)
+	}
+	if sent.Channel != ChannelWhatsApp || sent.Attempts != 1 || sent.Message == "" {
+		t.Fatalf("unexpected sent item: %+v", sent)
+	}
+
+	failed := res.Items[1]
+	if failed.EventID != "2" || failed.ContactName != "Ana" || failed.Outcome != OutcomeFailed {
+		t.Fatalf("expected event 2 failed for Ana, got %+v", failed)
+	}
+	if !errors.Is(failed.Err, domain.ErrInvalidPhone) {
+		t.Fatalf("expected ErrInvalidPhone, got %v", failed.Err)
+	}
+
+	if res.Items[2].Outcome != OutcomeSkipped {
+		t.Fatalf("expected event 3 skipped, got %+v", res.Items[2])
+	}
+}
+
+func TestRunDailyGreetings_WrapsErrorsWithEventContext(t *testing.T) {
+	now := time.Date(2026, 1, 16, 9, 0, 0, 0, time.UTC)
+
+	cal := fakeCalendar{
+		events: []CalendarEvent{
+			{ID: "evt-7", Title: "Ana", Description: "context: sin teléfono", StartDate: now},
+		},
+	}
+
+	uc := RunDailyGreetings{
+		Calendar:  cal,
+		Parser:    EventParser{},
+		Generator: fakeGenerator{text: "🎉"},
+		Sender:    &fakeSender{},
+		Clock:     fakeClock{t: now},
+	}
+
+	res := uc.Run(context.Background())
+
+	if len(res.Errors) != 1 {
+		t.Fatalf("expected 1 error, got %d", len(res.Errors))
+	}
+
+	err := res.Errors[0]
+	if !errors.Is(err, domain.ErrMissingPhone) {
+		t.Fatalf("expected ErrMissingPhone, got %v", err)
+	}
+
+	var evErr *EventError
+	if !errors.As(err, &evErr) {
+		t.Fatalf("expected *EventError, got %T", err)
+	}
+	if evErr.Stage != StageParse || evErr.EventID != "evt-7" || evErr.Title != "Ana" {
+		t.Fatalf("unexpected event error: %+v", evErr)
+	}
+}
+
+func TestRunDailyGreetings_WrapsFetchAndSendErrors(t *testing.T) {
+	now := time.Date(2026, 1, 16, 9, 0, 0, 0, time.UTC)
+	boom := errors.New("boom")
+
+	res := RunDailyGreetings{
+		Calendar: fakeCalendar{err: boom},
+		Clock:    fakeClock{t: now},
+	}.Run(context.Background())
+
+	if StageOf(res.Errors[0]) != StageFetch || !errors.Is(res.Errors[0], boom) {
+		t.Fatalf("expected fetch error wrapping cause, got %v", res.Errors[0])
+	}
+
+	res = RunDailyGreetings{
+		Calendar: fakeCalendar{events: []CalendarEvent{
+			{ID: "1", Title: "Pepe", Description: "phone: +34600111222", StartDate: now},
+		}},
+		Parser:    EventParser{},
+		Generator: fakeGenerator{text: "🎉"},
+		Sender:    &fakeSender{err: boom},
+		Clock:     fakeClock{t: now},
+	}.Run(context.Background())
+
+	if StageOf(res.Errors[0]) != StageSend || !errors.Is(res.Errors[0], boom) {
+		t.Fatalf("expected send error wrapping cause, got %v", res.Errors[0])
+	}
+}
+
+func TestRunDailyGreetings_PartialFetchStillGreets(t *testing.T) {
+	now := time.Date(2026, 1, 16, 9, 0, 0, 0, time.UTC)
+	boom := errors.New("google down")
+
+	sender := &fakeSender{}
+	res := RunDailyGreetings{
+		Calendar: fakeCalendar{
+			events: []CalendarEvent{{ID: "1", Title: "Pepe", Description: "phone: +34600111222", StartDate: now}},
+			err:    &PartialFetchError{Errs: []error{boom}},
+		},
+		Parser:    EventParser{},
+		Generator: fakeGenerator{text: "🎉"},
+		Sender:    sender,
+		Clock:     fakeClock{t: now},
+	}.Run(context.Background())
+
+	if res.Sent != 1 || len(sender.sent) != 1 {
+		t.Fatalf("expected the healthy source to be greeted, got %+v", res)
+	}
+	if res.Failed != 1 || len(res.Errors) != 1 {
+		t.Fatalf("expected one failed source, got %+v", res)
+	}
+	if StageOf(res.Errors[0]) != StageFetch || !errors.Is(res.Errors[0], boom) {
+		t.Fatalf("expected fetch error wrapping cause, got %v", res.Errors[0])
+	}
+}
+
+func TestRunDailyGreetings_StaleCacheReportsAge(t *testing.T) {
+	now := time.Date(2026, 1, 16, 9, 0, 0, 0, time.UTC)
+	boom := errors.New("google down")
+
+	res := RunDailyGreetings{
+		Calendar: fakeCalendar{
+			events: []CalendarEvent{{ID: "1", Title: "Pepe", Description: "phone: +34600111222", StartDate: now}},
+			err:    &StaleFetchError{CachedAt: now.Add(-26 * time.Hour), Err: boom},
+		},
+		Parser:    EventParser{},
+		Generator: fakeGenerator{text: "🎉"},
+		Sender:    &fakeSender{},
+		Clock:     fakeClock{t: now},
+	}.Run(context.Background())
+
+	if res.Sent != 1 || res.Failed != 0 {
+		t.Fatalf("expected cached events to be greeted without failures, got %+v", res)
+	}
+	if !res.FromCache || res.CacheAge != 26*time.Hour {
+		t.Fatalf("expected cache age 26h, got FromCache=%v CacheAge=%v", res.FromCache, res.CacheAge)
+	}
+	if len(res.Warnings) != 1 || !errors.Is(res.Warnings[0], boom) {
+		t.Fatalf("expected upstream error as warning, got %v", res.Warnings)
+	}
+}
+
+type fakeRecorder struct {
+	recorded []string
+	to       []string
+	err      error
+}
+
+func (f *fakeRecorder) RecordGreeting(ctx context.Context, ev CalendarEvent, date time.Time, channel, to string) error {
+	f.recorded = append(f.recorded, ev.ID+"@"+date.Format("2006-01-02")+" via "+channel+" to "+to)
+	f.to = append(f.to, to)
+	return f.err
+}
+
+func TestRunDailyGreetings_RecordsAndSkipsGreeted(t *testing.T) {
+	now := time.Date(2026, 1, 16, 9, 0, 0, 0, time.UTC)
+	rec := &fakeRecorder{}
+	sender := &fakeSender{}
+
+	res := RunDailyGreetings{
+		Calendar: fakeCalendar{events: []CalendarEvent{
+			{ID: "1", Title: "Pepe", Description: "phone: +34600111222", StartDate: now},
+			{ID: "2", Title: "Ana", Description: "phone: +34600333444", StartDate: now, Greeted: true},
+		}},
+		Parser:    EventParser{},
+		Generator: fakeGenerator{text: "🎉"},
+		Sender:    sender,
+		Recorder:  rec,
+		Clock:     fakeClock{t: now},
+	}.Run(context.Background())
+
+	if res.Sent != 1 || res.Skipped != 1 || len(sender.sent) != 1 {
+		t.Fatalf("expected Pepe sent and Ana skipped, got %+v", res)
+	}
+	if !errors.Is(res.Items[1].Err, ErrAlreadyGreeted) {
+		t.Errorf("expected ErrAlreadyGreeted for Ana, got %v", res.Items[1].Err)
+	}
+	if len(rec.recorded) != 1 || rec.recorded[0] != "1@2026-01-16 via whatsapp to +34600111222" {
+		t.Errorf("recorded = %v", rec.recorded)
+	}
+}
+
+func TestRunDailyGreetings_RecordFailureIsAWarning(t *testing.T) {
+	now := time.Date(2026, 1, 16, 9, 0, 0, 0, time.UTC)
+	boom := errors.New("insufficient scope")
+
+	res := RunDailyGreetings{
+		Calendar:  fakeCalendar{events: []CalendarEvent{{ID: "1", Title: "Pepe", Description: "phone: +34600111222", StartDate: now}}},
+		Parser:    EventParser{},
+		Generator: fakeGenerator{text: "🎉"},
+		Sender:    &fakeSender{},
+		Recorder:  &fakeRecorder{err: boom},
+		Clock:     fakeClock{t: now},
+	}.Run(context.Background())
+
+	if res.Sent != 1 || res.Failed != 0 {
+		t.Fatalf("expected the greeting to count as sent, got %+v", res)
+	}
+	if len(res.Warnings) != 1 || !errors.Is(res.Warnings[0], boom) || StageOf(res.Warnings[0]) != StageRecord {
+		t.Fatalf("expected a record-stage warning, got %v", res.Warnings)
+	}
+}
+
+type fakeGroupSender struct {
+	fakeSender
+	posts []string
+}
+
+func (f *fakeGroupSender) SendGroupText(ctx context.Context, group domain.Group, text string) error {
+	f.posts = append(f.posts, group.String()+": "+text)
+	return nil
+}
+
+type recipientGenerator struct{}
+
+func (recipientGenerator) Generate(ctx context.Context, in MessageInput) (domain.GreetingMessage, error) {
+	return domain.NewGreetingMessage(string(in.Recipient) + " " + in.Name), nil
+}
+
+func TestRunDailyGreetings_PostsInGroups(t *testing.T) {
+	now := time.Date(2026, 1, 16, 9, 0, 0, 0, time.UTC)
+
+	cal := fakeCalendar{
+		events: []CalendarEvent{
+			{ID: "1", Title: "Pepe", Description: "phone: +34600111222\ngroup: team@g.us", StartDate: now},
+			{ID: "2", Title: "Ana", Description: "group: team@g.us", StartDate: now},
+		},
+	}
+
+	sender := &fakeGroupSender{}
+	uc := RunDailyGreetings{
+		Calendar:  cal,
+		Parser:    EventParser{},
+		Generator: recipientGenerator{},
+		Sender:    sender,
+		Clock:     fakeClock{t: now},
+	}
+
+	res := uc.Run(context.Background())
+
+	if res.Total != 2 || res.Sent != 3 || res.Failed != 0 {
+		t.Fatalf("total/sent/failed = %d/%d/%d, want 2/3/0: %v", res.Total, res.Sent, res.Failed, res.Errors)
+	}
+	if len(sender.sent) != 1 || sender.sent[0].text != "direct Pepe" {
+		t.Errorf("direct messages = %+v", sender.sent)
+	}
+	if len(sender.posts) != 2 || sender.posts[0] != "team@g.us: group Pepe" || sender.posts[1] != "team@g.us: group Ana" {
+		t.Errorf("group posts = %v", sender.posts)
+	}
+	if res.Items[1].Group != "team@g.us" || res.Items[1].Phone != "" {
+		t.Errorf("group item = %+v", res.Items[1])
+	}
+}
+
+func TestRunDailyGreetings_GroupNeedsGroupSender(t *testing.T) {
+	now := time.Date(2026, 1, 16, 9, 0, 0, 0, time.UTC)
+
+	uc := RunDailyGreetings{
+		Calendar: fakeCalendar{events: []CalendarEvent{
+			{ID: "1", Title: "Pepe", Description: "phone: +34600111222\ngroup: team@g.us", StartDate: now},
+		}},
+		Parser:    EventParser{},
+		Generator: fakeGenerator{},
+		Sender:    &fakeSender{},
+		Clock:     fakeClock{t: now},
+	}
+
+	res := uc.Run(context.Background())
+
+	if res.Sent != 1 || res.Failed != 1 || !errors.Is(res.Errors[0], ErrGroupsUnsupported) {
+		t.Fatalf("sent/failed = %d/%d, errors %v", res.Sent, res.Failed, res.Errors)
+	}
+	if StageOf(res.Errors[0]) != StageSend {
+		t.Errorf("stage = %s, want send", StageOf(res.Errors[0]))
+	}
+}
+
+type namesGenerator struct{}
+
+func (namesGenerator) Generate(ctx context.Context, in MessageInput) (domain.GreetingMessage, error) {
+	if len(in.Names) > 0 {
+		return domain.NewGreetingMessage("Feliz cumple " + strings.Join(in.Names, " y ")), nil
+	}
+	return domain.NewGreetingMessage("Feliz cumple " + in.Name), nil
+}
+
+func TestRunDailyGreetings_PeopleIndividualOrJoint(t *testing.T) {
+	now := time.Date(2026, 1, 16, 9, 0, 0, 0, time.UTC)
+	cal := fakeCalendar{events: []CalendarEvent{
+		{ID: "1", Title: "Gemelos", Description: "people: Ana +34600111222, Luis +34600333444", StartDate: now},
+	}}
+
+	for _, tc := range []struct {
+		joint bool
+		want  []string
+	}{
+		{false, []string{"Feliz cumple Ana", "Feliz cumple Luis"}},
+		{true, []string{"Feliz cumple Ana y Luis", "Feliz cumple Ana y Luis"}},
+	} {
+		sender := &fakeSender{}
+		uc := RunDailyGreetings{
+			Calendar:  cal,
+			Parser:    EventParser{},
+			Generator: namesGenerator{},
+			Sender:    sender,
+			Clock:     fakeClock{t: now},
+			Joint:     tc.joint,
+		}
+
+		res := uc.Run(context.Background())
+
+		if res.Total != 1 || res.Sent != 2 {
+			t.Fatalf("joint=%v: total/sent = %d/%d, want 1/2", tc.joint, res.Total, res.Sent)
+		}
+		if sender.sent[0].to != "+34600111222" || sender.sent[1].to != "+34600333444" {
+			t.Errorf("joint=%v: recipients = %+v", tc.joint, sender.sent)
+		}
+		for i, want := range tc.want {
+			if sender.sent[i].text != want {
+				t.Errorf("joint=%v: message %d = %q, want %q", tc.joint, i, sender.sent[i].text, want)
+			}
+		}
+		if res.Items[1].ContactName != "Luis" {
+			t.Errorf("joint=%v: item contact = %q", tc.joint, res.Items[1].ContactName)
+		}
+	}
+}
+
+// flakySender fails the messages to failTo and sends the others.
+type flakySender struct {
+	fakeSender
+	failTo string
+}
+
+func (f *flakySender) SendText(ctx context.Context, to domain.Phone, text string) error {
+	if to.String() == f.failTo {
+		return errors.New("network down")
+	}
+	return f.fakeSender.SendText(ctx, to, text)
+}
+
+func TestRunDailyGreetings_PartialDeliveryIsRetried(t *testing.T) {
+	now := time.Date(2026, 1, 16, 9, 0, 0, 0, time.UTC)
+	ev := CalendarEvent{ID: "1", Title: "Gemelos", Description: "people: Ana +34600111222, Luis +34600333444", StartDate: now}
+	rec := &fakeRecorder{}
+
+	run := func(sender WhatsAppSender) RunResult {
+		// The provider reports back who was recorded, like a write-back would.
+		ev.GreetedTo = rec.to
+		return RunDailyGreetings{
+			Calendar:  fakeCalendar{events: []CalendarEvent{ev}},
+			Parser:    EventParser{},
+			Generator: fakeGenerator{text: "🎉"},
+			Sender:    sender,
+			Recorder:  rec,
+			Clock:     fakeClock{t: now},
+		}.Run(context.Background())
+	}
+
+	res := run(&flakySender{failTo: "+34600333444"})
+	if res.Sent != 1 || res.Failed != 1 {
+		t.Fatalf("expected Ana sent and Luis failed, got sent %d failed %d", res.Sent, res.Failed)
+	}
+	if len(rec.to) != 1 || rec.to[0] != "+34600111222" {
+		t.Fatalf("expected only Ana recorded, got %v", rec.to)
+	}
+
+	sender := &fakeSender{}
+	res = run(sender)
+	if res.Sent != 1 || res.Skipped != 1 || len(sender.sent) != 1 || sender.sent[0].to != "+34600333444" {
+		t.Fatalf("expected the retry to greet only Luis, got %+v", sender.sent)
+	}
+}
+
+func TestRunDailyGreetings_FailedGroupPostDoesNotResendDirect(t *testing.T) {
+	now := time.Date(2026, 1, 16, 9, 0, 0, 0, time.UTC)
+	ev := CalendarEvent{ID: "1", Title: "Pepe", Description: "phone: +34600111222\ngroup: team@g.us", StartDate: now}
+	rec := &fakeRecorder{}
+
+	run := func(sender WhatsAppSender) RunResult {
+		ev.GreetedTo = rec.to
+		return RunDailyGreetings{
+			Calendar:  fakeCalendar{events: []CalendarEvent{ev}},
+			Parser:    EventParser{},
+			Generator: fakeGenerator{text: "🎉"},
+			Sender:    sender,
+			Recorder:  rec,
+			Clock:     fakeClock{t: now},
+		}.Run(context.Background())
+	}
+
+	// A sender without groups: the direct message goes out, the post fails.
+	if res := run(&fakeSender{}); res.Sent != 1 || res.Failed != 1 {
+		t.Fatalf("expected the direct message sent and the post failed, got %+v", res)
+	}
+
+	sender := &fakeGroupSender{}
+	res := run(sender)
+	if len(sender.sent) != 0 {
+		t.Fatalf("expected no second direct message, got %+v", sender.sent)
+	}
+	if res.Sent != 1 || len(sender.posts) != 1 {
+		t.Fatalf("expected the retry to post in the group, got %+v", res)
+	}
+	if len(rec.to) != 2 || rec.to[1] != "group:team@g.us" {
+		t.Fatalf("expected the post recorded by group, got %v", rec.to)
+	}
+}
+
+func TestRunDailyGreetings_DryRunWithoutPreviewerFails(t *testing.T) {
+	now := time.Date(2026, 1, 16, 9, 0, 0, 0, time.UTC)
+	uc := RunDailyGreetings{
+		Calendar:  fakeCalendar{events: []CalendarEvent{{ID: "1", Title: "Pepe", Description: "phone: +34600111222", StartDate: now}}},
+		Parser:    EventParser{},
+		Generator: fakeGenerator{text: "🎉"},
+		Sender:    failingSender{t: t},
+		Clock:     fakeClock{t: now},
+		DryRun:    true,
+	}
+
+	res := uc.Run(context.Background())
+
+	if res.Failed != 1 || len(res.Errors) != 1 || !errors.Is(res.Errors[0], ErrNoPreviewer) {
+		t.Fatalf("expected one ErrNoPreviewer failure, got %+v", res)
+	}
+}
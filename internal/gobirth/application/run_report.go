@@ -0,0 +1,49 @@
+package application
+
+import "time"
+
+const ChannelWhatsApp = "whatsapp"
+
+type Outcome string
+
+const (
+	OutcomeSent      Outcome = "sent"
+	OutcomePreviewed Outcome = "previewed"
+	OutcomeSkipped   Outcome = "skipped"
+	OutcomeFailed    Outcome = "failed"
+)
+
+// ItemResult describes what happened to a single message during a run: the
+// direct message of an event, or its post in Group. Phone is always masked
+// so the report can be shipped to logs or monitoring.
+type ItemResult struct {
+	EventID     string
+	ContactName string
+	Phone       string
+	Group       string
+	Channel     string
+	Outcome     Outcome
+	Message     string
+	Err         error
+	Attempts    int
+	Duration    time.Duration
+}
+
+func (res *RunResult) record(item ItemResult) {
+	res.Items = append(res.Items, item)
+
+	switch item.Outcome {
+	case OutcomeSent:
+		res.Sent++
+		if item.Err != nil {
+			res.Warnings = append(res.Warnings, item.Err)
+		}
+	case OutcomePreviewed, OutcomeSkipped:
+		res.Skipped++
+	case OutcomeFailed:
+		res.Failed++
+		if item.Err != nil {
+			res.Errors = append(res.Errors, item.Err)
+		}
+	}
+}
@@ -0,0 +1,484 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+const redacted = "REDACTED"
+
+// Config is the full runtime configuration of gobirth. Values are layered
+// with the precedence flags > GOBIRTH_* env vars > config file > defaults;
+// this package handles everything but flags, which belong to the CLI.
+type Config struct {
+	Timezone  string         `yaml:"timezone"`
+	DryRun    bool           `yaml:"dry_run"`
+	MaxPerRun int            `yaml:"max_per_run"`
+	Output    string         `yaml:"output"`
+	Calendar  CalendarConfig `yaml:"calendar"`
+	Message   MessageConfig  `yaml:"message"`
+	Sender    SenderConfig   `yaml:"sender"`
+	Schedule  ScheduleConfig `yaml:"schedule"`
+	Secrets   SecretsConfig  `yaml:"secrets"`
+}
+
+type CalendarConfig struct {
+	Provider string       `yaml:"provider"`
+	File     string       `yaml:"file"`
+	ICS      string       `yaml:"ics"`
+	VCard    string       `yaml:"vcard"`
+	CSV      CSVConfig    `yaml:"csv"`
+	CalDAV   CalDAVConfig `yaml:"caldav"`
+	Google   GoogleConfig `yaml:"google"`
+	// Sources lists the providers merged by the multi provider, highest
+	// precedence first.
+	Sources []string    `yaml:"sources"`
+	Cache   CacheConfig `yaml:"cache"`
+	// NameDays lists the countries ("es", "gr", "pl") whose name days are
+	// added for the contacts of the calendar, matched by first name. The
+	// datasets cover common names on fixed dates, not full calendars.
+	NameDays []string `yaml:"name_days"`
+	// Filter narrows the file and google providers down to greetings.
+	Filter FilterConfig `yaml:"filter"`
+}
+
+// FilterConfig drops calendar events that are not greetings. Tags are
+// description hashtags without '#'; titles are Go regular expressions.
+type FilterConfig struct {
+	IncludeColors []string `yaml:"include_colors"`
+	ExcludeColors []string `yaml:"exclude_colors"`
+	IncludeTags   []string `yaml:"include_tags"`
+	ExcludeTags   []string `yaml:"exclude_tags"`
+	IncludeTitle  string   `yaml:"include_title"`
+	ExcludeTitle  string   `yaml:"exclude_title"`
+	// Kind keeps only "all-day" or "timed" events; empty keeps both.
+	Kind string `yaml:"kind"`
+}
+
+// CacheConfig keeps the last successful fetch on disk so runs still work
+// when the calendar is unreachable.
+type CacheConfig struct {
+	Enabled bool   `yaml:"enabled"`
+	Path    string `yaml:"path"`
+	Days    int    `yaml:"days"`
+}
+
+// CSVConfig describes a CSV/TSV contact list. Empty columns use the
+// provider defaults (name, birthday, phone, context).
+type CSVConfig struct {
+	Path        string           `yaml:"path"`
+	Delimiter   string           `yaml:"delimiter"`
+	Columns     CSVColumnsConfig `yaml:"columns"`
+	DateFormats []string         `yaml:"date_formats"`
+}
+
+type CSVColumnsConfig struct {
+	Name     string `yaml:"name"`
+	Birthday string `yaml:"birthday"`
+	Phone    string `yaml:"phone"`
+	Context  string `yaml:"context"`
+}
+
+// CalDAVConfig points at a CalDAV server. Password may be left empty and
+// kept in the secret store under "caldav-password" instead.
+type CalDAVConfig struct {
+	URL      string `yaml:"url"`
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+	Calendar string `yaml:"calendar"`
+}
+
+type GoogleConfig struct {
+	Calendar       string `yaml:"calendar"`
+	CalendarID     string `yaml:"calendar_id"`
+	Auth           string `yaml:"auth"`
+	Credentials    string `yaml:"credentials"`
+	Token          string `yaml:"token"`
+	ServiceAccount string `yaml:"service_account"`
+	Subject        string `yaml:"subject"`
+	// ContactsToken and ContactsCache are used by the google-contacts
+	// provider, whose token carries the contacts scope instead.
+	ContactsToken string `yaml:"contacts_token"`
+	ContactsCache string `yaml:"contacts_cache"`
+	// Sync keeps a local copy of the calendar in SyncState and only fetches
+	// the changes on each run.
+	Sync      bool   `yaml:"sync"`
+	SyncState string `yaml:"sync_state"`
+	// WriteBack records each sent greeting on its event: "property" (a
+	// private extended property) or "description" (a "greeted:" line).
+	// Empty disables it. It needs write access to the calendar.
+	WriteBack string `yaml:"write_back"`
+}
+
+type MessageConfig struct {
+	Emoji    string `yaml:"emoji"`
+	Template string `yaml:"template"`
+	// Templates overrides Template per occasion, keyed by occasion name
+	// ("anniversary", "name-day", "graduation"...).
+	Templates map[string]string `yaml:"templates"`
+	// GroupTemplate is used for posts in the group of an event.
+	GroupTemplate string `yaml:"group_template"`
+	// Joint sends the people of a "people:" line one shared message naming
+	// all of them instead of one message each.
+	Joint bool `yaml:"joint"`
+}
+
+type SenderConfig struct {
+	Provider string `yaml:"provider"`
+	// Token may be left empty and kept in the secret store under
+	// "sender-token" instead, like the CalDAV password.
+	Token string `yaml:"token"`
+}
+
+// SecretsConfig selects where credentials such as OAuth tokens are kept.
+// The encrypted-file passphrase is never read from the file; it comes from
+// GOBIRTH_SECRETS_PASSPHRASE.
+type SecretsConfig struct {
+	Backend string `yaml:"backend"`
+	Path    string `yaml:"path"`
+}
+
+type ScheduleConfig struct {
+	// Time is the local time of day (HH:MM) a run is considered to happen
+	// at, and when gobirth daemon sends the greetings.
+	Time string `yaml:"time"`
+}
+
+// Dir returns the gobirth directory inside the user config dir
+// (e.g. ~/.config/gobirth).
+func Dir() string {
+	cfgDir, _ := os.UserConfigDir()
+	return filepath.Join(cfgDir, "gobirth")
+}
+
+func DefaultPath() string {
+	return filepath.Join(Dir(), "config.yaml")
+}
+
+func Default() Config {
+	dir := Dir()
+
+	return Config{
+		Timezone:  "Europe/Madrid",
+		DryRun:    true,
+		MaxPerRun: 10,
+		Output:    "text",
+		Calendar: CalendarConfig{
+			Provider: "file",
+			Cache: CacheConfig{
+				Path: filepath.Join(dir, "calendar-cache.json"),
+				Days: 60,
+			},
+			Google: GoogleConfig{
+				Calendar:    "gobirth",
+				Auth:        "oauth",
+				Credentials: filepath.Join(dir, "credentials.json"),
+				Token:       filepath.Join(dir, "token.json"),
+
+				ContactsToken: filepath.Join(dir, "token-contacts.json"),
+				ContactsCache: filepath.Join(dir, "contacts-cache.json"),
+
+				SyncState: filepath.Join(dir, "calendar-sync.json"),
+			},
+		},
+		Message: MessageConfig{
+			Emoji: "🎉",
+		},
+		Sender: SenderConfig{
+			Provider: "stdout",
+		},
+		Schedule: ScheduleConfig{
+			Time: "09:00",
+		},
+		Secrets: SecretsConfig{
+			Backend: "file",
+			Path:    filepath.Join(dir, "secrets.age"),
+		},
+	}
+}
+
+// Load builds a Config from defaults, the config file at path and the
+// environment. A missing file is only an error when required is true, so the
+// default location can be absent.
+func Load(path string, required bool, lookupEnv func(string) (string, bool)) (Config, error) {
+	cfg := Default()
+
+	if err := LoadFile(path, &cfg); err != nil {
+		if required || !errors.Is(err, os.ErrNotExist) {
+			return Config{}, err
+		}
+	}
+
+	if err := ApplyEnv(&cfg, lookupEnv); err != nil {
+		return Config{}, err
+	}
+
+	cfg.ExpandPaths()
+	return cfg, nil
+}
+
+// LoadFile overlays the YAML file at path onto cfg. Unknown keys are rejected
+// so typos don't silently fall back to defaults.
+func LoadFile(path string, cfg *Config) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("config: open %s: %w", path, err)
+	}
+	defer f.Close()
+
+	dec := yaml.NewDecoder(f)
+	dec.KnownFields(true)
+	if err := dec.Decode(cfg); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		return fmt.Errorf("config: decode %s: %w", path, err)
+	}
+	return nil
+}
+
+// ExpandPaths replaces a leading "~" in every path setting with the home
+// directory. Load runs it once the file and the environment are applied;
+// callers that layer more values on top, such as flags, run it again.
+func (cfg *Config) ExpandPaths() {
+	cfg.Calendar.File = expandHome(cfg.Calendar.File)
+	cfg.Calendar.ICS = expandHome(cfg.Calendar.ICS)
+	cfg.Calendar.VCard = expandHome(cfg.Calendar.VCard)
+	cfg.Calendar.CSV.Path = expandHome(cfg.Calendar.CSV.Path)
+	cfg.Calendar.Cache.Path = expandHome(cfg.Calendar.Cache.Path)
+	cfg.Calendar.Google.Credentials = expandHome(cfg.Calendar.Google.Credentials)
+	cfg.Calendar.Google.Token = expandHome(cfg.Calendar.Google.Token)
+	cfg.Calendar.Google.ServiceAccount = expandHome(cfg.Calendar.Google.ServiceAccount)
+	cfg.Calendar.Google.ContactsToken = expandHome(cfg.Calendar.Google.ContactsToken)
+	cfg.Calendar.Google.ContactsCache = expandHome(cfg.Calendar.Google.ContactsCache)
+	cfg.Calendar.Google.SyncState = expandHome(cfg.Calendar.Google.SyncState)
+	cfg.Secrets.Path = expandHome(cfg.Secrets.Path)
+}
+
+// Validate reports every problem found in cfg at once.
+func (cfg Config) Validate() error {
+	var errs []error
+
+	if _, err := cfg.Location(); err != nil {
+		errs = append(errs, err)
+	}
+	if _, _, err := cfg.ScheduleTime(); err != nil {
+		errs = append(errs, err)
+	}
+	if cfg.MaxPerRun < 0 {
+		errs = append(errs, fmt.Errorf("max_per_run must be >= 0, got %d", cfg.MaxPerRun))
+	}
+
+	switch cfg.Output {
+	case "text", "json", "table":
+	default:
+		errs = append(errs, fmt.Errorf("output must be text|json|table, got %q", cfg.Output))
+	}
+
+	errs = append(errs, cfg.validateCalendar(cfg.Calendar.Provider)...)
+	if c := cfg.Calendar.Cache; c.Enabled {
+		if c.Path == "" {
+			errs = append(errs, errors.New("calendar.cache.path is required when calendar.cache.enabled=true"))
+		}
+		if c.Days <= 0 {
+			errs = append(errs, fmt.Errorf("calendar.cache.days must be > 0, got %d", c.Days))
+		}
+	}
+
+	errs = append(errs, cfg.Calendar.Filter.validate()...)
+
+	switch cfg.Secrets.Backend {
+	case "file", "keyring", "env":
+	case "encrypted-file":
+		if cfg.Secrets.Path == "" {
+			errs = append(errs, errors.New("secrets.path is required when secrets.backend=encrypted-file"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("secrets.backend must be file|keyring|encrypted-file|env, got %q", cfg.Secrets.Backend))
+	}
+
+	switch cfg.Sender.Provider {
+	case "stdout":
+	default:
+		errs = append(errs, fmt.Errorf("sender.provider must be stdout, got %q", cfg.Sender.Provider))
+	}
+
+	return errors.Join(errs...)
+}
+
+// CheckTemplates runs check, usually the message generator's parser, on
+// every message template, and reports every failure at once. Validate does
+// not, since the template language belongs to the generator.
+func (m MessageConfig) CheckTemplates(check func(text string) error) error {
+	var errs []error
+	try := func(key, text string) {
+		if text == "" {
+			return
+		}
+		if err := check(text); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", key, err))
+		}
+	}
+
+	try("message.template", m.Template)
+	try("message.group_template", m.GroupTemplate)
+	occasions := make([]string, 0, len(m.Templates))
+	for occ := range m.Templates {
+		occasions = append(occasions, occ)
+	}
+	sort.Strings(occasions)
+	for _, occ := range occasions {
+		try("message.templates."+occ, m.Templates[occ])
+	}
+	return errors.Join(errs...)
+}
+
+// validateCalendar checks the settings of one calendar provider; for multi
+// it checks every source.
+func (cfg Config) validateCalendar(provider string) []error {
+	var errs []error
+
+	switch provider {
+	case "multi":
+		if len(cfg.Calendar.Sources) == 0 {
+			errs = append(errs, errors.New("calendar.sources is required when calendar.provider=multi"))
+		}
+		seen := map[string]bool{}
+		for _, src := range cfg.Calendar.Sources {
+			switch {
+			case src == "multi":
+				errs = append(errs, errors.New("calendar.sources cannot contain multi"))
+			case seen[src]:
+				errs = append(errs, fmt.Errorf("calendar.sources lists %q twice", src))
+			default:
+				seen[src] = true
+				errs = append(errs, cfg.validateCalendar(src)...)
+			}
+		}
+	case "file":
+		if cfg.Calendar.File == "" {
+			errs = append(errs, errors.New("calendar.file is required when calendar.provider=file"))
+		}
+	case "ics":
+		if cfg.Calendar.ICS == "" {
+			errs = append(errs, errors.New("calendar.ics is required when calendar.provider=ics"))
+		}
+	case "vcard":
+		if cfg.Calendar.VCard == "" {
+			errs = append(errs, errors.New("calendar.vcard is required when calendar.provider=vcard"))
+		}
+	case "csv":
+		if cfg.Calendar.CSV.Path == "" {
+			errs = append(errs, errors.New("calendar.csv.path is required when calendar.provider=csv"))
+		}
+		if d := cfg.Calendar.CSV.Delimiter; d != "" && d != `\t` && utf8.RuneCountInString(d) != 1 {
+			errs = append(errs, fmt.Errorf("calendar.csv.delimiter must be a single character or \\t, got %q", d))
+		}
+	case "caldav":
+		if strings.TrimSpace(cfg.Calendar.CalDAV.URL) == "" {
+			errs = append(errs, errors.New("calendar.caldav.url is required when calendar.provider=caldav"))
+		}
+		if strings.TrimSpace(cfg.Calendar.CalDAV.Calendar) == "" {
+			errs = append(errs, errors.New("calendar.caldav.calendar is required when calendar.provider=caldav"))
+		}
+	case "google", "google-contacts":
+		g := cfg.Calendar.Google
+		if provider == "google" && strings.TrimSpace(g.Calendar) == "" && strings.TrimSpace(g.CalendarID) == "" {
+			errs = append(errs, errors.New("calendar.google.calendar or calendar.google.calendar_id is required when calendar.provider=google"))
+		}
+		if provider == "google" && g.Sync && g.SyncState == "" {
+			errs = append(errs, errors.New("calendar.google.sync_state is required when calendar.google.sync=true"))
+		}
+		switch g.WriteBack {
+		case "", "property", "description":
+		default:
+			errs = append(errs, fmt.Errorf("calendar.google.write_back must be property|description or empty, got %q", g.WriteBack))
+		}
+		switch g.Auth {
+		case "oauth", "device":
+			if g.Credentials == "" {
+				errs = append(errs, errors.New("calendar.google.credentials is required for oauth and device auth"))
+			}
+		case "service-account":
+			if g.ServiceAccount == "" {
+				errs = append(errs, errors.New("calendar.google.service_account is required for service-account auth"))
+			}
+		case "adc":
+		default:
+			errs = append(errs, fmt.Errorf("calendar.google.auth must be oauth|device|service-account|adc, got %q", g.Auth))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("calendar.provider must be file|ics|vcard|csv|caldav|google|google-contacts|multi, got %q", provider))
+	}
+
+	return errs
+}
+
+func (cfg Config) Location() (*time.Location, error) {
+	loc, err := time.LoadLocation(cfg.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("timezone %q: %w", cfg.Timezone, err)
+	}
+	return loc, nil
+}
+
+func (cfg Config) ScheduleTime() (hour, minute int, err error) {
+	t, err := time.Parse("15:04", cfg.Schedule.Time)
+	if err != nil {
+		return 0, 0, fmt.Errorf("schedule.time must be HH:MM, got %q", cfg.Schedule.Time)
+	}
+	return t.Hour(), t.Minute(), nil
+}
+
+// Redacted returns a copy of cfg with secrets replaced, safe to print.
+func (cfg Config) Redacted() Config {
+	if cfg.Sender.Token != "" {
+		cfg.Sender.Token = redacted
+	}
+	if cfg.Calendar.CalDAV.Password != "" {
+		cfg.Calendar.CalDAV.Password = redacted
+	}
+	return cfg
+}
+
+func (cfg Config) YAML() ([]byte, error) {
+	return yaml.Marshal(cfg)
+}
+
+func expandHome(path string) string {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, path[1:])
+}
+
+func (f FilterConfig) validate() []error {
+	var errs []error
+	if _, err := regexp.Compile(f.IncludeTitle); err != nil {
+		errs = append(errs, fmt.Errorf("calendar.filter.include_title: %w", err))
+	}
+	if _, err := regexp.Compile(f.ExcludeTitle); err != nil {
+		errs = append(errs, fmt.Errorf("calendar.filter.exclude_title: %w", err))
+	}
+	switch f.Kind {
+	case "", "all-day", "timed":
+	default:
+		errs = append(errs, fmt.Errorf("calendar.filter.kind must be all-day|timed or empty, got %q", f.Kind))
+	}
+	return errs
+}
@@ -0,0 +1,180 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	return path
+}
+
+func envMap(m map[string]string) func(string) (string, bool) {
+	return func(k string) (string, bool) {
+		v, ok := m[k]
+		return v, ok
+	}
+}
+
+func TestLoad_FileOverridesDefaults(t *testing.T) {
+	path := writeConfig(t, `
+timezone: UTC
+max_per_run: 3
+calendar:
+  provider: file
+  file: /tmp/events.json
+`)
+
+	cfg, err := Load(path, true, nil)
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+
+	if cfg.Timezone != "UTC" || cfg.MaxPerRun != 3 || cfg.Calendar.File != "/tmp/events.json" {
+		t.Fatalf("file values not applied: %+v", cfg)
+	}
+	if cfg.Message.Emoji != "🎉" || cfg.Calendar.Google.Calendar != "gobirth" {
+		t.Fatalf("defaults lost: %+v", cfg)
+	}
+}
+
+func TestLoad_EnvOverridesFile(t *testing.T) {
+	path := writeConfig(t, "timezone: UTC\ndry_run: true\n")
+
+	cfg, err := Load(path, true, envMap(map[string]string{
+		"GOBIRTH_TIMEZONE": "Europe/Athens",
+		"GOBIRTH_DRY_RUN":  "false",
+	}))
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+
+	if cfg.Timezone != "Europe/Athens" || cfg.DryRun {
+		t.Fatalf("env values not applied: %+v", cfg)
+	}
+}
+
+func TestLoad_MissingOptionalFileUsesDefaults(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nope.yaml")
+
+	if _, err := Load(path, false, nil); err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if _, err := Load(path, true, nil); err == nil {
+		t.Fatalf("expected error for missing required file")
+	}
+}
+
+func TestLoad_RejectsUnknownKeys(t *testing.T) {
+	path := writeConfig(t, "timezon: UTC\n")
+
+	if _, err := Load(path, true, nil); err == nil {
+		t.Fatalf("expected error for unknown key")
+	}
+}
+
+func TestValidate_ReportsAllProblems(t *testing.T) {
+	cfg := Default()
+	cfg.Timezone = "Mars/Olympus"
+	cfg.Calendar.Provider = "file"
+	cfg.Schedule.Time = "25:99"
+	cfg.Calendar.Filter.ExcludeTitle = "(aniversario"
+	cfg.Calendar.Filter.Kind = "weekly"
+
+	err := cfg.Validate()
+	if err == nil {
+		t.Fatalf("expected validation error")
+	}
+
+	for _, want := range []string{"timezone", "calendar.file", "schedule.time", "calendar.filter.exclude_title", "calendar.filter.kind"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Fatalf("expected error to mention %q, got %v", want, err)
+		}
+	}
+}
+
+func TestValidate_MultiChecksEverySource(t *testing.T) {
+	cfg := Default()
+	cfg.Calendar.Provider = "multi"
+	cfg.Calendar.Sources = []string{"vcard", "file", "vcard", "multi"}
+	cfg.Calendar.File = "events.json"
+
+	err := cfg.Validate()
+	if err == nil {
+		t.Fatalf("expected validation error")
+	}
+
+	for _, want := range []string{"calendar.vcard is required", `lists "vcard" twice`, "cannot contain multi"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Fatalf("expected error to mention %q, got %v", want, err)
+		}
+	}
+	if strings.Contains(err.Error(), "calendar.file") {
+		t.Fatalf("file source is valid, got %v", err)
+	}
+}
+
+func TestCheckTemplates_ReportsEveryBrokenTemplate(t *testing.T) {
+	m := MessageConfig{
+		Template:      "Happy birthday, {{.Name}}!",
+		GroupTemplate: "{{.Name",
+		Templates:     map[string]string{"anniversary": "{{shout .Name}}"},
+	}
+	check := func(text string) error {
+		if strings.Contains(text, "{{.Name}}") {
+			return nil
+		}
+		return errors.New("bad template")
+	}
+
+	err := m.CheckTemplates(check)
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+	for _, want := range []string{"message.group_template", "message.templates.anniversary"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Fatalf("expected error to mention %q, got %v", want, err)
+		}
+	}
+	if strings.Contains(err.Error(), "message.template:") {
+		t.Fatalf("message.template is valid, got %v", err)
+	}
+}
+
+func TestLoad_ExpandsHomeInEnvPaths(t *testing.T) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		t.Skipf("no home directory: %v", err)
+	}
+	env := map[string]string{"GOBIRTH_CALENDAR_ICS": "~/birthdays.ics"}
+
+	cfg, err := Load(filepath.Join(t.TempDir(), "missing.yaml"), false, envMap(env))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := filepath.Join(home, "birthdays.ics"); cfg.Calendar.ICS != want {
+		t.Fatalf("ICS = %q, want %q", cfg.Calendar.ICS, want)
+	}
+}
+
+func TestRedacted_HidesSecrets(t *testing.T) {
+	cfg := Default()
+	cfg.Sender.Token = "s3cret"
+
+	out, err := cfg.Redacted().YAML()
+	if err != nil {
+		t.Fatalf("expected nil error, got %v", err)
+	}
+	if strings.Contains(string(out), "s3cret") {
+		t.Fatalf("expected token to be redacted, got:\n%s", out)
+	}
+}
@@ -0,0 +1,126 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const EnvPrefix = "GOBIRTH_"
+
+// ApplyEnv overlays GOBIRTH_* environment variables onto cfg. lookupEnv is
+// usually os.LookupEnv.
+func ApplyEnv(cfg *Config, lookupEnv func(string) (string, bool)) error {
+	if lookupEnv == nil {
+		return nil
+	}
+
+	str := func(name string, dst *string) {
+		if v, ok := lookupEnv(EnvPrefix + name); ok {
+			*dst = v
+		}
+	}
+
+	str("TIMEZONE", &cfg.Timezone)
+	str("OUTPUT", &cfg.Output)
+	str("CALENDAR_PROVIDER", &cfg.Calendar.Provider)
+	str("CALENDAR_FILE", &cfg.Calendar.File)
+	str("CALENDAR_ICS", &cfg.Calendar.ICS)
+	str("CALENDAR_VCARD", &cfg.Calendar.VCard)
+	str("CALENDAR_CSV", &cfg.Calendar.CSV.Path)
+
+	if v, ok := lookupEnv(EnvPrefix + "CALENDAR_SOURCES"); ok {
+		cfg.Calendar.Sources = SplitList(v)
+	}
+	list := func(name string, dst *[]string) {
+		if v, ok := lookupEnv(EnvPrefix + name); ok {
+			*dst = SplitList(v)
+		}
+	}
+	list("NAME_DAYS", &cfg.Calendar.NameDays)
+	list("FILTER_INCLUDE_COLORS", &cfg.Calendar.Filter.IncludeColors)
+	list("FILTER_EXCLUDE_COLORS", &cfg.Calendar.Filter.ExcludeColors)
+	list("FILTER_INCLUDE_TAGS", &cfg.Calendar.Filter.IncludeTags)
+	list("FILTER_EXCLUDE_TAGS", &cfg.Calendar.Filter.ExcludeTags)
+	str("FILTER_INCLUDE_TITLE", &cfg.Calendar.Filter.IncludeTitle)
+	str("FILTER_EXCLUDE_TITLE", &cfg.Calendar.Filter.ExcludeTitle)
+	str("FILTER_KIND", &cfg.Calendar.Filter.Kind)
+	str("CALDAV_URL", &cfg.Calendar.CalDAV.URL)
+	str("CALDAV_USERNAME", &cfg.Calendar.CalDAV.Username)
+	str("CALDAV_PASSWORD", &cfg.Calendar.CalDAV.Password)
+	str("CALDAV_CALENDAR", &cfg.Calendar.CalDAV.Calendar)
+	str("GOOGLE_CALENDAR", &cfg.Calendar.Google.Calendar)
+	str("GOOGLE_CALENDAR_ID", &cfg.Calendar.Google.CalendarID)
+	str("GOOGLE_AUTH", &cfg.Calendar.Google.Auth)
+	str("GOOGLE_SERVICE_ACCOUNT", &cfg.Calendar.Google.ServiceAccount)
+	str("GOOGLE_SUBJECT", &cfg.Calendar.Google.Subject)
+	str("GOOGLE_CREDENTIALS", &cfg.Calendar.Google.Credentials)
+	str("GOOGLE_TOKEN", &cfg.Calendar.Google.Token)
+	str("GOOGLE_CONTACTS_TOKEN", &cfg.Calendar.Google.ContactsToken)
+	str("GOOGLE_CONTACTS_CACHE", &cfg.Calendar.Google.ContactsCache)
+	str("GOOGLE_SYNC_STATE", &cfg.Calendar.Google.SyncState)
+	str("GOOGLE_WRITE_BACK", &cfg.Calendar.Google.WriteBack)
+	str("EMOJI", &cfg.Message.Emoji)
+	str("TEMPLATE", &cfg.Message.Template)
+	str("GROUP_TEMPLATE", &cfg.Message.GroupTemplate)
+	str("SENDER_PROVIDER", &cfg.Sender.Provider)
+	str("SENDER_TOKEN", &cfg.Sender.Token)
+	str("SCHEDULE_TIME", &cfg.Schedule.Time)
+	str("SECRETS_BACKEND", &cfg.Secrets.Backend)
+	str("SECRETS_PATH", &cfg.Secrets.Path)
+
+	str("CALENDAR_CACHE_PATH", &cfg.Calendar.Cache.Path)
+
+	if v, ok := lookupEnv(EnvPrefix + "CALENDAR_CACHE"); ok {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("config: %sCALENDAR_CACHE: %w", EnvPrefix, err)
+		}
+		cfg.Calendar.Cache.Enabled = b
+	}
+
+	if v, ok := lookupEnv(EnvPrefix + "GOOGLE_SYNC"); ok {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("config: %sGOOGLE_SYNC: %w", EnvPrefix, err)
+		}
+		cfg.Calendar.Google.Sync = b
+	}
+
+	if v, ok := lookupEnv(EnvPrefix + "JOINT"); ok {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("config: %sJOINT: %w", EnvPrefix, err)
+		}
+		cfg.Message.Joint = b
+	}
+
+	if v, ok := lookupEnv(EnvPrefix + "DRY_RUN"); ok {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("config: %sDRY_RUN: %w", EnvPrefix, err)
+		}
+		cfg.DryRun = b
+	}
+
+	if v, ok := lookupEnv(EnvPrefix + "MAX_PER_RUN"); ok {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("config: %sMAX_PER_RUN: %w", EnvPrefix, err)
+		}
+		cfg.MaxPerRun = n
+	}
+
+	return nil
+}
+
+// SplitList splits a comma-separated list, dropping empty items.
+func SplitList(v string) []string {
+	var out []string
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
@@ -1,11 +1,43 @@
 package domain
 
-import "strings"
+import (
+	"strings"
+	"time"
+)
 
 type Contact struct {
-	name    string
-	phone   Phone
-	context string
+	name     string
+	phone    Phone
+	context  string
+	occasion Occasion
+	since    int
+	group    Group
+	people   []Person
+}
+
+// Person is one of the people an event greets.
+type Person struct {
+	name  string
+	phone Phone
+}
+
+func NewPerson(name string, phone Phone) (Person, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return Person{}, ErrMissingName
+	}
+	if phone.IsZero() {
+		return Person{}, ErrMissingPhone
+	}
+	return Person{name: name, phone: phone}, nil
+}
+
+func (person Person) Name() string {
+	return person.name
+}
+
+func (person Person) Phone() Phone {
+	return person.phone
 }
 
 func NewContact(name string, phone Phone, context string) (Contact, error) {
@@ -15,12 +47,37 @@ func NewContact(name string, phone Phone, context string) (Contact, error) {
 	}
 
 	return Contact{
-		name:    name,
-		phone:   phone,
-		context: strings.TrimSpace(context),
+		name:     name,
+		phone:    phone,
+		context:  strings.TrimSpace(context),
+		occasion: OccasionBirthday,
 	}, nil
 }
 
+// WithOccasion returns a copy of the contact celebrating occasion, which
+// started in year since (0 when unknown).
+func (contact Contact) WithOccasion(occasion Occasion, since int) Contact {
+	if occasion == "" {
+		occasion = OccasionBirthday
+	}
+	contact.occasion = occasion
+	contact.since = since
+	return contact
+}
+
+// WithGroup returns a copy of the contact also greeted in group.
+func (contact Contact) WithGroup(group Group) Contact {
+	contact.group = group
+	return contact
+}
+
+// WithPeople returns a copy of the contact greeting several people, such as
+// twins or a couple, instead of the contact phone alone.
+func (contact Contact) WithPeople(people []Person) Contact {
+	contact.people = append([]Person(nil), people...)
+	return contact
+}
+
 func (contact Contact) Name() string {
 	return contact.name
 }
@@ -32,3 +89,38 @@ func (contact Contact) Phone() Phone {
 func (contact Contact) Context() string {
 	return contact.context
 }
+
+// People are the recipients of direct messages: the listed people, or the
+// contact itself when it has a phone.
+func (contact Contact) People() []Person {
+	if len(contact.people) > 0 {
+		return append([]Person(nil), contact.people...)
+	}
+	if contact.phone.IsZero() {
+		return nil
+	}
+	return []Person{{name: contact.name, phone: contact.phone}}
+}
+
+// Group is the chat group where the greeting is also posted; zero when
+// there is none.
+func (contact Contact) Group() Group {
+	return contact.group
+}
+
+func (contact Contact) Occasion() Occasion {
+	return contact.occasion
+}
+
+// Years is the count celebrated on date: the age on a birthday, the years
+// married on a wedding anniversary. It is 0 when the start year is unknown
+// or the occasion does not count years.
+func (contact Contact) Years(date time.Time) int {
+	if contact.since <= 0 || !contact.occasion.Yearly() {
+		return 0
+	}
+	if n := date.Year() - contact.since; n > 0 {
+		return n
+	}
+	return 0
+}
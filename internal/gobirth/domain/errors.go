@@ -6,4 +6,6 @@ var (
 	ErrMissingPhone = errors.New("missing phone number")
 	ErrInvalidPhone = errors.New("invalid phone number")
 	ErrMissingName  = errors.New("missing contact name")
+	ErrInvalidYear  = errors.New("invalid year")
+	ErrInvalidGroup = errors.New("invalid group ID")
 )
@@ -0,0 +1,37 @@
+package domain
+
+import (
+	"strings"
+	"unicode"
+)
+
+// RecipientKind tells a direct message to the contact from a post in a
+// chat group.
+type RecipientKind string
+
+const (
+	RecipientDirect RecipientKind = "direct"
+	RecipientGroup  RecipientKind = "group"
+)
+
+// Group is the ID of a chat group in the messaging service, e.g. a WhatsApp
+// group JID ("120363012345678901@g.us") or a Telegram chat ID ("-1001234").
+type Group struct {
+	id string
+}
+
+func NewGroup(id string) (Group, error) {
+	id = strings.TrimSpace(id)
+	if id == "" || strings.IndexFunc(id, unicode.IsSpace) >= 0 {
+		return Group{}, ErrInvalidGroup
+	}
+	return Group{id: id}, nil
+}
+
+func (group Group) String() string {
+	return group.id
+}
+
+func (group Group) IsZero() bool {
+	return group.id == ""
+}
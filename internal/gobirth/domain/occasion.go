@@ -0,0 +1,73 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+// Occasion is what an event celebrates. The known occasions have their own
+// messages; any other value is a custom occasion named by the value itself,
+// e.g. "graduation".
+type Occasion string
+
+const (
+	OccasionBirthday           Occasion = "birthday"
+	OccasionWeddingAnniversary Occasion = "wedding-anniversary"
+	OccasionWorkAnniversary    Occasion = "work-anniversary"
+	OccasionNameDay            Occasion = "name-day"
+)
+
+var occasionAliases = map[string]Occasion{
+	"birthday":            OccasionBirthday,
+	"cumpleaños":          OccasionBirthday,
+	"cumple":              OccasionBirthday,
+	"anniversary":         OccasionWeddingAnniversary,
+	"wedding anniversary": OccasionWeddingAnniversary,
+	"wedding":             OccasionWeddingAnniversary,
+	"aniversario":         OccasionWeddingAnniversary,
+	"work anniversary":    OccasionWorkAnniversary,
+	"work":                OccasionWorkAnniversary,
+	"name day":            OccasionNameDay,
+	"nameday":             OccasionNameDay,
+	"santo":               OccasionNameDay,
+	"onomástica":          OccasionNameDay,
+}
+
+// ParseOccasion normalizes the occasion written on an event. Empty means a
+// birthday; spaces, dashes and underscores are interchangeable.
+func ParseOccasion(s string) Occasion {
+	key := strings.Join(strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return r == ' ' || r == '-' || r == '_'
+	}), " ")
+	if key == "" {
+		return OccasionBirthday
+	}
+	if o, ok := occasionAliases[key]; ok {
+		return o
+	}
+	return Occasion(key)
+}
+
+// Known reports whether o is one of the predefined occasions.
+func (o Occasion) Known() bool {
+	switch o {
+	case OccasionBirthday, OccasionWeddingAnniversary, OccasionWorkAnniversary, OccasionNameDay:
+		return true
+	}
+	return false
+}
+
+// Yearly reports whether the occasion counts years: name days don't.
+func (o Occasion) Yearly() bool {
+	return o != OccasionNameDay
+}
+
+// Anniversary returns the date of a yearly occasion on month/day in year.
+// Feb 29 falls on Feb 28 in common years.
+func Anniversary(year int, month time.Month, day int, loc *time.Location) time.Time {
+	t := time.Date(year, month, day, 0, 0, 0, 0, loc)
+	if t.Month() != month {
+		t = time.Date(year, month+1, 0, 0, 0, 0, 0, loc)
+	}
+	return t
+}
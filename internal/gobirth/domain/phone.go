@@ -27,6 +27,37 @@ func (phone Phone) String() string {
 	return phone.value
 }
 
+// IsZero reports a contact without phone, greeted only in a group.
+func (phone Phone) IsZero() bool {
+	return phone.value == ""
+}
+
+func (phone Phone) Masked() string {
+	return MaskPhone(phone.value)
+}
+
+// NormalizePhone drops the spaces, dashes, dots and parentheses people write
+// in phone numbers, so "+34 (600) 111-222" becomes "+34600111222". Any other
+// character is kept for NewPhone to reject.
+func NormalizePhone(phone string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case ' ', '\t', '-', '.', '(', ')':
+			return -1
+		}
+		return r
+	}, strings.TrimSpace(phone))
+}
+
+func MaskPhone(phone string) string {
+	phone = strings.TrimSpace(phone)
+	if len(phone) <= 6 {
+		return strings.Repeat("*", len(phone))
+	}
+
+	return phone[:3] + strings.Repeat("*", len(phone)-6) + phone[len(phone)-3:]
+}
+
 func isValidE164(phone string) bool {
 	if !strings.HasPrefix(phone, "+") {
 		return false